@@ -93,11 +93,17 @@ type ClientInterface interface {
 	DisableBlocking(ctx context.Context, params *DisableBlockingParams, reqEditors ...RequestEditorFn) (*http.Response, error)
 
 	// EnableBlocking request
-	EnableBlocking(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+	EnableBlocking(ctx context.Context, params *EnableBlockingParams, reqEditors ...RequestEditorFn) (*http.Response, error)
 
 	// BlockingStatus request
 	BlockingStatus(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
 
+	// UnblockRequests request
+	UnblockRequests(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// DismissUnblockRequest request
+	DismissUnblockRequest(ctx context.Context, params *DismissUnblockRequestParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
 	// CacheFlush request
 	CacheFlush(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
 
@@ -122,8 +128,8 @@ func (c *Client) DisableBlocking(ctx context.Context, params *DisableBlockingPar
 	return c.Client.Do(req)
 }
 
-func (c *Client) EnableBlocking(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
-	req, err := NewEnableBlockingRequest(c.Server)
+func (c *Client) EnableBlocking(ctx context.Context, params *EnableBlockingParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewEnableBlockingRequest(c.Server, params)
 	if err != nil {
 		return nil, err
 	}
@@ -146,6 +152,30 @@ func (c *Client) BlockingStatus(ctx context.Context, reqEditors ...RequestEditor
 	return c.Client.Do(req)
 }
 
+func (c *Client) UnblockRequests(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewUnblockRequestsRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) DismissUnblockRequest(ctx context.Context, params *DismissUnblockRequestParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewDismissUnblockRequestRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
 func (c *Client) CacheFlush(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
 	req, err := NewCacheFlushRequest(c.Server)
 	if err != nil {
@@ -248,6 +278,38 @@ func NewDisableBlockingRequest(server string, params *DisableBlockingParams) (*h
 
 		}
 
+		if params.Client != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "client", runtime.ParamLocationQuery, *params.Client); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Profile != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "profile", runtime.ParamLocationQuery, *params.Profile); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
 		queryURL.RawQuery = queryValues.Encode()
 	}
 
@@ -260,7 +322,7 @@ func NewDisableBlockingRequest(server string, params *DisableBlockingParams) (*h
 }
 
 // NewEnableBlockingRequest generates requests for EnableBlocking
-func NewEnableBlockingRequest(server string) (*http.Request, error) {
+func NewEnableBlockingRequest(server string, params *EnableBlockingParams) (*http.Request, error) {
 	var err error
 
 	serverURL, err := url.Parse(server)
@@ -278,6 +340,44 @@ func NewEnableBlockingRequest(server string) (*http.Request, error) {
 		return nil, err
 	}
 
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Client != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "client", runtime.ParamLocationQuery, *params.Client); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Profile != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "profile", runtime.ParamLocationQuery, *params.Profile); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
 	req, err := http.NewRequest("GET", queryURL.String(), nil)
 	if err != nil {
 		return nil, err
@@ -313,6 +413,78 @@ func NewBlockingStatusRequest(server string) (*http.Request, error) {
 	return req, nil
 }
 
+// NewUnblockRequestsRequest generates requests for UnblockRequests
+func NewUnblockRequestsRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/blocking/unblock-requests")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewDismissUnblockRequestRequest generates requests for DismissUnblockRequest
+func NewDismissUnblockRequestRequest(server string, params *DismissUnblockRequestParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/blocking/unblock-requests/dismiss")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "id", runtime.ParamLocationQuery, params.Id); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
 // NewCacheFlushRequest generates requests for CacheFlush
 func NewCacheFlushRequest(server string) (*http.Request, error) {
 	var err error
@@ -454,11 +626,17 @@ type ClientWithResponsesInterface interface {
 	DisableBlockingWithResponse(ctx context.Context, params *DisableBlockingParams, reqEditors ...RequestEditorFn) (*DisableBlockingResponse, error)
 
 	// EnableBlockingWithResponse request
-	EnableBlockingWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*EnableBlockingResponse, error)
+	EnableBlockingWithResponse(ctx context.Context, params *EnableBlockingParams, reqEditors ...RequestEditorFn) (*EnableBlockingResponse, error)
 
 	// BlockingStatusWithResponse request
 	BlockingStatusWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*BlockingStatusResponse, error)
 
+	// UnblockRequestsWithResponse request
+	UnblockRequestsWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*UnblockRequestsResponse, error)
+
+	// DismissUnblockRequestWithResponse request
+	DismissUnblockRequestWithResponse(ctx context.Context, params *DismissUnblockRequestParams, reqEditors ...RequestEditorFn) (*DismissUnblockRequestResponse, error)
+
 	// CacheFlushWithResponse request
 	CacheFlushWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*CacheFlushResponse, error)
 
@@ -535,6 +713,49 @@ func (r BlockingStatusResponse) StatusCode() int {
 	return 0
 }
 
+type UnblockRequestsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *[]ApiUnblockRequest
+}
+
+// Status returns HTTPResponse.Status
+func (r UnblockRequestsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r UnblockRequestsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type DismissUnblockRequestResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+}
+
+// Status returns HTTPResponse.Status
+func (r DismissUnblockRequestResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r DismissUnblockRequestResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
 type CacheFlushResponse struct {
 	Body         []byte
 	HTTPResponse *http.Response
@@ -609,8 +830,8 @@ func (c *ClientWithResponses) DisableBlockingWithResponse(ctx context.Context, p
 }
 
 // EnableBlockingWithResponse request returning *EnableBlockingResponse
-func (c *ClientWithResponses) EnableBlockingWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*EnableBlockingResponse, error) {
-	rsp, err := c.EnableBlocking(ctx, reqEditors...)
+func (c *ClientWithResponses) EnableBlockingWithResponse(ctx context.Context, params *EnableBlockingParams, reqEditors ...RequestEditorFn) (*EnableBlockingResponse, error) {
+	rsp, err := c.EnableBlocking(ctx, params, reqEditors...)
 	if err != nil {
 		return nil, err
 	}
@@ -626,6 +847,24 @@ func (c *ClientWithResponses) BlockingStatusWithResponse(ctx context.Context, re
 	return ParseBlockingStatusResponse(rsp)
 }
 
+// UnblockRequestsWithResponse request returning *UnblockRequestsResponse
+func (c *ClientWithResponses) UnblockRequestsWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*UnblockRequestsResponse, error) {
+	rsp, err := c.UnblockRequests(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseUnblockRequestsResponse(rsp)
+}
+
+// DismissUnblockRequestWithResponse request returning *DismissUnblockRequestResponse
+func (c *ClientWithResponses) DismissUnblockRequestWithResponse(ctx context.Context, params *DismissUnblockRequestParams, reqEditors ...RequestEditorFn) (*DismissUnblockRequestResponse, error) {
+	rsp, err := c.DismissUnblockRequest(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDismissUnblockRequestResponse(rsp)
+}
+
 // CacheFlushWithResponse request returning *CacheFlushResponse
 func (c *ClientWithResponses) CacheFlushWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*CacheFlushResponse, error) {
 	rsp, err := c.CacheFlush(ctx, reqEditors...)
@@ -719,6 +958,48 @@ func ParseBlockingStatusResponse(rsp *http.Response) (*BlockingStatusResponse, e
 	return response, nil
 }
 
+// ParseUnblockRequestsResponse parses an HTTP response from a UnblockRequestsWithResponse call
+func ParseUnblockRequestsResponse(rsp *http.Response) (*UnblockRequestsResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &UnblockRequestsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest []ApiUnblockRequest
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseDismissUnblockRequestResponse parses an HTTP response from a DismissUnblockRequestWithResponse call
+func ParseDismissUnblockRequestResponse(rsp *http.Response) (*DismissUnblockRequestResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &DismissUnblockRequestResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	return response, nil
+}
+
 // ParseCacheFlushResponse parses an HTTP response from a CacheFlushWithResponse call
 func ParseCacheFlushResponse(rsp *http.Response) (*CacheFlushResponse, error) {
 	bodyBytes, err := io.ReadAll(rsp.Body)
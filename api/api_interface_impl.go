@@ -29,13 +29,49 @@ type BlockingStatus struct {
 	DisabledGroups []string
 	// If blocking is temporarily disabled: amount of seconds until blocking will be enabled
 	AutoEnableInSec int
+	// Names of the blocking schedules within one of their windows
+	ActiveSchedules []string
+	// Blocking pauses of single clients or profiles
+	Pauses []BlockingPause
+}
+
+// BlockingPause represents the blocking pause of a single client or profile
+type BlockingPause struct {
+	// Paused client, empty for a profile
+	Client string
+	// Paused profile, empty for a client
+	Profile string
+	// Paused group names, all groups if empty
+	Groups []string
+	// If the pause is temporary: amount of seconds until blocking will be enabled
+	AutoEnableInSec int
+}
+
+// UnblockRequest represents the request of a client to unblock a blocked domain
+type UnblockRequest struct {
+	// ID of the unblock request
+	ID string
+	// Blocked domain
+	Domain string
+	// Name or IP address of the requesting client
+	Client string
+	// bGuard reason for the block
+	Reason string
+	// Comment of the client, may be empty
+	Comment string
+	// Time of the request
+	RequestedAt time.Time
 }
 
 // BlockingControl interface to control the blocking status
 type BlockingControl interface {
 	EnableBlocking(ctx context.Context)
 	DisableBlocking(ctx context.Context, duration time.Duration, disableGroups []string) error
+	PauseBlocking(ctx context.Context, client, profile string, duration time.Duration, pauseGroups []string) error
+	ResumeBlocking(ctx context.Context, client, profile string) error
 	BlockingStatus() BlockingStatus
+	UnblockRequests() []UnblockRequest
+	DismissUnblockRequest(id string) error
 }
 
 // ListRefresher interface to control the list refresh
@@ -107,7 +143,13 @@ func (i *OpenAPIInterfaceImpl) DisableBlocking(ctx context.Context,
 		groups = strings.Split(*request.Params.Groups, ",")
 	}
 
-	err = i.control.DisableBlocking(ctx, duration, groups)
+	client, profile := valueOrEmpty(request.Params.Client), valueOrEmpty(request.Params.Profile)
+
+	if client != "" || profile != "" {
+		err = i.control.PauseBlocking(ctx, client, profile, duration, groups)
+	} else {
+		err = i.control.DisableBlocking(ctx, duration, groups)
+	}
 
 	if err != nil {
 		return DisableBlocking400TextResponse(log.EscapeInput(err.Error())), nil
@@ -116,9 +158,19 @@ func (i *OpenAPIInterfaceImpl) DisableBlocking(ctx context.Context,
 	return DisableBlocking200Response{}, nil
 }
 
-func (i *OpenAPIInterfaceImpl) EnableBlocking(ctx context.Context, _ EnableBlockingRequestObject,
+func (i *OpenAPIInterfaceImpl) EnableBlocking(ctx context.Context, request EnableBlockingRequestObject,
 ) (EnableBlockingResponseObject, error) {
-	i.control.EnableBlocking(ctx)
+	client, profile := valueOrEmpty(request.Params.Client), valueOrEmpty(request.Params.Profile)
+
+	if client == "" && profile == "" {
+		i.control.EnableBlocking(ctx)
+
+		return EnableBlocking200Response{}, nil
+	}
+
+	if err := i.control.ResumeBlocking(ctx, client, profile); err != nil {
+		return EnableBlocking400TextResponse(log.EscapeInput(err.Error())), nil
+	}
 
 	return EnableBlocking200Response{}, nil
 }
@@ -139,9 +191,87 @@ func (i *OpenAPIInterfaceImpl) BlockingStatus(_ context.Context, _ BlockingStatu
 		result.DisabledGroups = &blStatus.DisabledGroups
 	}
 
+	if len(blStatus.ActiveSchedules) > 0 {
+		result.ActiveSchedules = &blStatus.ActiveSchedules
+	}
+
+	if len(blStatus.Pauses) > 0 {
+		pauses := make([]ApiBlockingPause, 0, len(blStatus.Pauses))
+
+		for _, pause := range blStatus.Pauses {
+			pauses = append(pauses, apiBlockingPause(pause))
+		}
+
+		result.Pauses = &pauses
+	}
+
 	return BlockingStatus200JSONResponse(result), nil
 }
 
+func apiBlockingPause(pause BlockingPause) ApiBlockingPause {
+	result := ApiBlockingPause{}
+
+	if pause.Client != "" {
+		result.Client = &pause.Client
+	}
+
+	if pause.Profile != "" {
+		result.Profile = &pause.Profile
+	}
+
+	if len(pause.Groups) > 0 {
+		result.Groups = &pause.Groups
+	}
+
+	if pause.AutoEnableInSec > 0 {
+		result.AutoEnableInSec = &pause.AutoEnableInSec
+	}
+
+	return result
+}
+
+func (i *OpenAPIInterfaceImpl) UnblockRequests(_ context.Context, _ UnblockRequestsRequestObject,
+) (UnblockRequestsResponseObject, error) {
+	requests := i.control.UnblockRequests()
+
+	result := make([]ApiUnblockRequest, 0, len(requests))
+
+	for _, request := range requests {
+		apiRequest := ApiUnblockRequest{
+			Id:          request.ID,
+			Domain:      request.Domain,
+			Client:      request.Client,
+			Reason:      request.Reason,
+			RequestedAt: request.RequestedAt,
+		}
+
+		if request.Comment != "" {
+			apiRequest.Comment = &request.Comment
+		}
+
+		result = append(result, apiRequest)
+	}
+
+	return UnblockRequests200JSONResponse(result), nil
+}
+
+func (i *OpenAPIInterfaceImpl) DismissUnblockRequest(_ context.Context, request DismissUnblockRequestRequestObject,
+) (DismissUnblockRequestResponseObject, error) {
+	if err := i.control.DismissUnblockRequest(request.Params.Id); err != nil {
+		return DismissUnblockRequest404TextResponse(log.EscapeInput(err.Error())), nil
+	}
+
+	return DismissUnblockRequest200Response{}, nil
+}
+
+func valueOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
+
 func (i *OpenAPIInterfaceImpl) ListRefresh(_ context.Context,
 	_ ListRefreshRequestObject,
 ) (ListRefreshResponseObject, error) {
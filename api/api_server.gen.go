@@ -21,10 +21,16 @@ type ServerInterface interface {
 	DisableBlocking(w http.ResponseWriter, r *http.Request, params DisableBlockingParams)
 	// Enable blocking
 	// (GET /blocking/enable)
-	EnableBlocking(w http.ResponseWriter, r *http.Request)
+	EnableBlocking(w http.ResponseWriter, r *http.Request, params EnableBlockingParams)
 	// Blocking status
 	// (GET /blocking/status)
 	BlockingStatus(w http.ResponseWriter, r *http.Request)
+	// Unblock requests
+	// (GET /blocking/unblock-requests)
+	UnblockRequests(w http.ResponseWriter, r *http.Request)
+	// Dismiss unblock request
+	// (POST /blocking/unblock-requests/dismiss)
+	DismissUnblockRequest(w http.ResponseWriter, r *http.Request, params DismissUnblockRequestParams)
 	// Clears the DNS response cache
 	// (POST /cache/flush)
 	CacheFlush(w http.ResponseWriter, r *http.Request)
@@ -48,7 +54,7 @@ func (_ Unimplemented) DisableBlocking(w http.ResponseWriter, r *http.Request, p
 
 // Enable blocking
 // (GET /blocking/enable)
-func (_ Unimplemented) EnableBlocking(w http.ResponseWriter, r *http.Request) {
+func (_ Unimplemented) EnableBlocking(w http.ResponseWriter, r *http.Request, params EnableBlockingParams) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
@@ -58,6 +64,18 @@ func (_ Unimplemented) BlockingStatus(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
+// Unblock requests
+// (GET /blocking/unblock-requests)
+func (_ Unimplemented) UnblockRequests(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Dismiss unblock request
+// (POST /blocking/unblock-requests/dismiss)
+func (_ Unimplemented) DismissUnblockRequest(w http.ResponseWriter, r *http.Request, params DismissUnblockRequestParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
 // Clears the DNS response cache
 // (POST /cache/flush)
 func (_ Unimplemented) CacheFlush(w http.ResponseWriter, r *http.Request) {
@@ -110,6 +128,22 @@ func (siw *ServerInterfaceWrapper) DisableBlocking(w http.ResponseWriter, r *htt
 		return
 	}
 
+	// ------------- Optional query parameter "client" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "client", r.URL.Query(), &params.Client)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "client", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "profile" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "profile", r.URL.Query(), &params.Profile)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "profile", Err: err})
+		return
+	}
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		siw.Handler.DisableBlocking(w, r, params)
 	}))
@@ -125,8 +159,29 @@ func (siw *ServerInterfaceWrapper) DisableBlocking(w http.ResponseWriter, r *htt
 func (siw *ServerInterfaceWrapper) EnableBlocking(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params EnableBlockingParams
+
+	// ------------- Optional query parameter "client" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "client", r.URL.Query(), &params.Client)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "client", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "profile" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "profile", r.URL.Query(), &params.Profile)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "profile", Err: err})
+		return
+	}
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.EnableBlocking(w, r)
+		siw.Handler.EnableBlocking(w, r, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -151,6 +206,56 @@ func (siw *ServerInterfaceWrapper) BlockingStatus(w http.ResponseWriter, r *http
 	handler.ServeHTTP(w, r.WithContext(ctx))
 }
 
+// UnblockRequests operation middleware
+func (siw *ServerInterfaceWrapper) UnblockRequests(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.UnblockRequests(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// DismissUnblockRequest operation middleware
+func (siw *ServerInterfaceWrapper) DismissUnblockRequest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DismissUnblockRequestParams
+
+	// ------------- Required query parameter "id" -------------
+
+	if paramValue := r.URL.Query().Get("id"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "id"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "id", r.URL.Query(), &params.Id)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DismissUnblockRequest(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
 // CacheFlush operation middleware
 func (siw *ServerInterfaceWrapper) CacheFlush(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -318,6 +423,12 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/blocking/status", wrapper.BlockingStatus)
 	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/blocking/unblock-requests", wrapper.UnblockRequests)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/blocking/unblock-requests/dismiss", wrapper.DismissUnblockRequest)
+	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/cache/flush", wrapper.CacheFlush)
 	})
@@ -358,6 +469,7 @@ func (response DisableBlocking400TextResponse) VisitDisableBlockingResponse(w ht
 }
 
 type EnableBlockingRequestObject struct {
+	Params EnableBlockingParams
 }
 
 type EnableBlockingResponseObject interface {
@@ -372,6 +484,16 @@ func (response EnableBlocking200Response) VisitEnableBlockingResponse(w http.Res
 	return nil
 }
 
+type EnableBlocking400TextResponse string
+
+func (response EnableBlocking400TextResponse) VisitEnableBlockingResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(400)
+
+	_, err := w.Write([]byte(response))
+	return err
+}
+
 type BlockingStatusRequestObject struct {
 }
 
@@ -388,6 +510,48 @@ func (response BlockingStatus200JSONResponse) VisitBlockingStatusResponse(w http
 	return json.NewEncoder(w).Encode(response)
 }
 
+type UnblockRequestsRequestObject struct {
+}
+
+type UnblockRequestsResponseObject interface {
+	VisitUnblockRequestsResponse(w http.ResponseWriter) error
+}
+
+type UnblockRequests200JSONResponse []ApiUnblockRequest
+
+func (response UnblockRequests200JSONResponse) VisitUnblockRequestsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type DismissUnblockRequestRequestObject struct {
+	Params DismissUnblockRequestParams
+}
+
+type DismissUnblockRequestResponseObject interface {
+	VisitDismissUnblockRequestResponse(w http.ResponseWriter) error
+}
+
+type DismissUnblockRequest200Response struct {
+}
+
+func (response DismissUnblockRequest200Response) VisitDismissUnblockRequestResponse(w http.ResponseWriter) error {
+	w.WriteHeader(200)
+	return nil
+}
+
+type DismissUnblockRequest404TextResponse string
+
+func (response DismissUnblockRequest404TextResponse) VisitDismissUnblockRequestResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(404)
+
+	_, err := w.Write([]byte(response))
+	return err
+}
+
 type CacheFlushRequestObject struct {
 }
 
@@ -466,6 +630,12 @@ type StrictServerInterface interface {
 	// Blocking status
 	// (GET /blocking/status)
 	BlockingStatus(ctx context.Context, request BlockingStatusRequestObject) (BlockingStatusResponseObject, error)
+	// Unblock requests
+	// (GET /blocking/unblock-requests)
+	UnblockRequests(ctx context.Context, request UnblockRequestsRequestObject) (UnblockRequestsResponseObject, error)
+	// Dismiss unblock request
+	// (POST /blocking/unblock-requests/dismiss)
+	DismissUnblockRequest(ctx context.Context, request DismissUnblockRequestRequestObject) (DismissUnblockRequestResponseObject, error)
 	// Clears the DNS response cache
 	// (POST /cache/flush)
 	CacheFlush(ctx context.Context, request CacheFlushRequestObject) (CacheFlushResponseObject, error)
@@ -533,9 +703,11 @@ func (sh *strictHandler) DisableBlocking(w http.ResponseWriter, r *http.Request,
 }
 
 // EnableBlocking operation middleware
-func (sh *strictHandler) EnableBlocking(w http.ResponseWriter, r *http.Request) {
+func (sh *strictHandler) EnableBlocking(w http.ResponseWriter, r *http.Request, params EnableBlockingParams) {
 	var request EnableBlockingRequestObject
 
+	request.Params = params
+
 	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
 		return sh.ssi.EnableBlocking(ctx, request.(EnableBlockingRequestObject))
 	}
@@ -580,6 +752,56 @@ func (sh *strictHandler) BlockingStatus(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// UnblockRequests operation middleware
+func (sh *strictHandler) UnblockRequests(w http.ResponseWriter, r *http.Request) {
+	var request UnblockRequestsRequestObject
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.UnblockRequests(ctx, request.(UnblockRequestsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "UnblockRequests")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(UnblockRequestsResponseObject); ok {
+		if err := validResponse.VisitUnblockRequestsResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// DismissUnblockRequest operation middleware
+func (sh *strictHandler) DismissUnblockRequest(w http.ResponseWriter, r *http.Request, params DismissUnblockRequestParams) {
+	var request DismissUnblockRequestRequestObject
+
+	request.Params = params
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.DismissUnblockRequest(ctx, request.(DismissUnblockRequestRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "DismissUnblockRequest")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(DismissUnblockRequestResponseObject); ok {
+		if err := validResponse.VisitDismissUnblockRequestResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
 // CacheFlush operation middleware
 func (sh *strictHandler) CacheFlush(w http.ResponseWriter, r *http.Request) {
 	var request CacheFlushRequestObject
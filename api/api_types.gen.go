@@ -3,8 +3,30 @@
 // Code generated by github.com/deepmap/oapi-codegen version v1.16.2 DO NOT EDIT.
 package api
 
+import (
+	"time"
+)
+
+// ApiBlockingPause defines model for api.BlockingPause.
+type ApiBlockingPause struct {
+	// AutoEnableInSec If the pause is temporary: amount of seconds until blocking will be enabled
+	AutoEnableInSec *int `json:"autoEnableInSec,omitempty"`
+
+	// Client Paused client
+	Client *string `json:"client,omitempty"`
+
+	// Groups Paused group names, all groups if empty
+	Groups *[]string `json:"groups,omitempty"`
+
+	// Profile Paused profile
+	Profile *string `json:"profile,omitempty"`
+}
+
 // ApiBlockingStatus defines model for api.BlockingStatus.
 type ApiBlockingStatus struct {
+	// ActiveSchedules Names of the blocking schedules within one of their windows
+	ActiveSchedules *[]string `json:"activeSchedules,omitempty"`
+
 	// AutoEnableInSec If blocking is temporary disabled: amount of seconds until blocking will be enabled
 	AutoEnableInSec *int `json:"autoEnableInSec,omitempty"`
 
@@ -13,6 +35,9 @@ type ApiBlockingStatus struct {
 
 	// Enabled True if blocking is enabled
 	Enabled bool `json:"enabled"`
+
+	// Pauses Blocking pauses of single clients or profiles
+	Pauses *[]ApiBlockingPause `json:"pauses,omitempty"`
 }
 
 // ApiQueryRequest defines model for api.QueryRequest.
@@ -39,6 +64,27 @@ type ApiQueryResult struct {
 	ReturnCode string `json:"returnCode"`
 }
 
+// ApiUnblockRequest defines model for api.UnblockRequest.
+type ApiUnblockRequest struct {
+	// Client Name or IP address of the requesting client
+	Client string `json:"client"`
+
+	// Comment Comment of the client
+	Comment *string `json:"comment,omitempty"`
+
+	// Domain Blocked domain
+	Domain string `json:"domain"`
+
+	// Id ID of the unblock request
+	Id string `json:"id"`
+
+	// Reason bGuard reason for the block (BLOCKED (group), ...)
+	Reason string `json:"reason"`
+
+	// RequestedAt Time of the request
+	RequestedAt time.Time `json:"requestedAt"`
+}
+
 // DisableBlockingParams defines parameters for DisableBlocking.
 type DisableBlockingParams struct {
 	// Duration duration of blocking (Example: 300s, 5m, 1h, 5m30s)
@@ -46,6 +92,27 @@ type DisableBlockingParams struct {
 
 	// Groups groups to disable (comma separated). If empty, disable all groups
 	Groups *string `form:"groups,omitempty" json:"groups,omitempty"`
+
+	// Client pause blocking only for this client (client name, IP address, subnet or MAC address)
+	Client *string `form:"client,omitempty" json:"client,omitempty"`
+
+	// Profile pause blocking only for the clients of this profile
+	Profile *string `form:"profile,omitempty" json:"profile,omitempty"`
+}
+
+// EnableBlockingParams defines parameters for EnableBlocking.
+type EnableBlockingParams struct {
+	// Client resume blocking only for this paused client
+	Client *string `form:"client,omitempty" json:"client,omitempty"`
+
+	// Profile resume blocking only for this paused profile
+	Profile *string `form:"profile,omitempty" json:"profile,omitempty"`
+}
+
+// DismissUnblockRequestParams defines parameters for DismissUnblockRequest.
+type DismissUnblockRequestParams struct {
+	// Id ID of the unblock request
+	Id string `form:"id" json:"id"`
 }
 
 // QueryJSONRequestBody defines body for Query for application/json ContentType.
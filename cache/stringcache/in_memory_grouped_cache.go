@@ -1,9 +1,15 @@
 package stringcache
 
-import "sync"
+import (
+	"net"
+	"sync"
+)
 
 type stringCacheFactoryFn func() cacheFactory
 
+// ASNLookup returns the number of the autonomous system of an IP
+type ASNLookup func(ip net.IP) (uint, bool)
+
 type InMemoryGroupedCache struct {
 	caches    map[string]stringCache
 	lock      sync.RWMutex
@@ -31,6 +37,23 @@ func NewInMemoryGroupedWildcardCache() *InMemoryGroupedCache {
 	}
 }
 
+func NewInMemoryGroupedIPCache() *InMemoryGroupedCache {
+	return &InMemoryGroupedCache{
+		caches:    make(map[string]stringCache),
+		factoryFn: newIPCacheFactory,
+	}
+}
+
+// NewInMemoryGroupedASNCache creates a cache of autonomous system numbers, lookup is nil without ASN database
+func NewInMemoryGroupedASNCache(lookup ASNLookup) *InMemoryGroupedCache {
+	return &InMemoryGroupedCache{
+		caches: make(map[string]stringCache),
+		factoryFn: func() cacheFactory {
+			return newASNCacheFactory(lookup)
+		},
+	}
+}
+
 func (c *InMemoryGroupedCache) ElementCount(group string) int {
 	c.lock.RLock()
 	cache, found := c.caches[group]
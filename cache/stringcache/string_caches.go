@@ -1,10 +1,13 @@
 package stringcache
 
 import (
+	"net"
+	"net/netip"
 	"regexp"
 	"sort"
 	"strings"
 
+	"github.com/Abiji-2020/bGuard/geoip"
 	"github.com/Abiji-2020/bGuard/log"
 	"github.com/Abiji-2020/bGuard/trie"
 )
@@ -253,3 +256,136 @@ func normalizeWildcard(domain string) string {
 
 	return domain
 }
+
+type ipCache struct {
+	trie *trie.IPTrie[struct{}]
+}
+
+func (cache ipCache) elementCount() int {
+	return cache.trie.Len()
+}
+
+func (cache ipCache) contains(searchString string) bool {
+	ip := net.ParseIP(searchString)
+	if ip == nil {
+		return false
+	}
+
+	_, found := cache.trie.LongestMatch(ip)
+	if found {
+		log.PrefixedLog("ip_cache").Debugf("IP range matched with '%s'", searchString)
+	}
+
+	return found
+}
+
+type ipCacheFactory struct {
+	trie *trie.IPTrie[struct{}]
+}
+
+func newIPCacheFactory() cacheFactory {
+	return &ipCacheFactory{
+		trie: trie.NewIPTrie[struct{}](),
+	}
+}
+
+func (r *ipCacheFactory) addEntry(entry string) bool {
+	if !strings.Contains(entry, "/") {
+		return false
+	}
+
+	prefix, err := netip.ParsePrefix(entry)
+	if err != nil {
+		return false
+	}
+
+	r.trie.Insert(prefix, struct{}{})
+
+	return true
+}
+
+func (r *ipCacheFactory) count() int {
+	return r.trie.Len()
+}
+
+func (r *ipCacheFactory) create() stringCache {
+	if r.trie.Len() == 0 {
+		return nil
+	}
+
+	return ipCache{r.trie}
+}
+
+type asnCache struct {
+	numbers map[uint]struct{}
+	lookup  ASNLookup
+}
+
+func (cache asnCache) elementCount() int {
+	return len(cache.numbers)
+}
+
+func (cache asnCache) contains(searchString string) bool {
+	ip := net.ParseIP(searchString)
+	if ip == nil {
+		return false
+	}
+
+	number, ok := cache.lookup(ip)
+	if !ok {
+		return false
+	}
+
+	_, found := cache.numbers[number]
+	if found {
+		log.PrefixedLog("asn_cache").Debugf("AS%d matched with '%s'", number, searchString)
+	}
+
+	return found
+}
+
+type asnCacheFactory struct {
+	numbers map[uint]struct{}
+	lookup  ASNLookup
+	warned  bool
+}
+
+func newASNCacheFactory(lookup ASNLookup) cacheFactory {
+	return &asnCacheFactory{
+		numbers: make(map[uint]struct{}),
+		lookup:  lookup,
+	}
+}
+
+func (r *asnCacheFactory) addEntry(entry string) bool {
+	number, ok := geoip.ParseASN(entry)
+	if !ok {
+		return false
+	}
+
+	if r.lookup == nil {
+		if !r.warned {
+			log.Log().Warnf("ASN entry '%s' ignored: no ASN database configured", entry)
+
+			r.warned = true
+		}
+
+		return true // invalid but handled
+	}
+
+	r.numbers[number] = struct{}{}
+
+	return true
+}
+
+func (r *asnCacheFactory) count() int {
+	return len(r.numbers)
+}
+
+func (r *asnCacheFactory) create() stringCache {
+	if len(r.numbers) == 0 {
+		return nil
+	}
+
+	return asnCache{r.numbers, r.lookup}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/Abiji-2020/bGuard/api"
 	"github.com/Abiji-2020/bGuard/log"
@@ -18,13 +19,16 @@ func newBlockingCommand() *cobra.Command {
 		Short:             "Control status of blocking resolver",
 		PersistentPreRunE: initConfigPreRun,
 	}
-	c.AddCommand(&cobra.Command{
+	enableCommand := &cobra.Command{
 		Use:     "enable",
 		Args:    cobra.NoArgs,
 		Aliases: []string{"on"},
 		Short:   "Enable blocking",
 		RunE:    enableBlocking,
-	})
+	}
+	enableCommand.Flags().StringP("client", "c", "", "resume blocking only for this paused client")
+	enableCommand.Flags().StringP("profile", "p", "", "resume blocking only for this paused profile")
+	c.AddCommand(enableCommand)
 
 	disableCommand := &cobra.Command{
 		Use:     "disable",
@@ -35,6 +39,8 @@ func newBlockingCommand() *cobra.Command {
 	}
 	disableCommand.Flags().DurationP("duration", "d", 0, "duration in min")
 	disableCommand.Flags().StringArrayP("groups", "g", []string{}, "blocking groups to disable")
+	disableCommand.Flags().StringP("client", "c", "", "pause blocking only for this client (name, IP, subnet or MAC)")
+	disableCommand.Flags().StringP("profile", "p", "", "pause blocking only for the clients of this profile")
 	c.AddCommand(disableCommand)
 
 	c.AddCommand(&cobra.Command{
@@ -44,16 +50,36 @@ func newBlockingCommand() *cobra.Command {
 		RunE:  statusBlocking,
 	})
 
+	c.AddCommand(&cobra.Command{
+		Use:   "requests",
+		Args:  cobra.NoArgs,
+		Short: "Print the unblock requests from the block page",
+		RunE:  unblockRequests,
+	})
+
+	c.AddCommand(&cobra.Command{
+		Use:   "dismiss <id>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Dismiss a reviewed unblock request",
+		RunE:  dismissUnblockRequest,
+	})
+
 	return c
 }
 
-func enableBlocking(_ *cobra.Command, _ []string) error {
+func enableBlocking(cmd *cobra.Command, _ []string) error {
+	clientName, _ := cmd.Flags().GetString("client")
+	profile, _ := cmd.Flags().GetString("profile")
+
 	client, err := api.NewClientWithResponses(apiURL())
 	if err != nil {
 		return fmt.Errorf("can't create client: %w", err)
 	}
 
-	resp, err := client.EnableBlockingWithResponse(context.Background())
+	resp, err := client.EnableBlockingWithResponse(context.Background(), &api.EnableBlockingParams{
+		Client:  &clientName,
+		Profile: &profile,
+	})
 	if err != nil {
 		return fmt.Errorf("can't execute %w", err)
 	}
@@ -64,6 +90,8 @@ func enableBlocking(_ *cobra.Command, _ []string) error {
 func disableBlocking(cmd *cobra.Command, _ []string) error {
 	duration, _ := cmd.Flags().GetDuration("duration")
 	groups, _ := cmd.Flags().GetStringArray("groups")
+	clientName, _ := cmd.Flags().GetString("client")
+	profile, _ := cmd.Flags().GetString("profile")
 
 	durationString := duration.String()
 	groupsString := strings.Join(groups, ",")
@@ -76,6 +104,8 @@ func disableBlocking(cmd *cobra.Command, _ []string) error {
 	resp, err := client.DisableBlockingWithResponse(context.Background(), &api.DisableBlockingParams{
 		Duration: &durationString,
 		Groups:   &groupsString,
+		Client:   &clientName,
+		Profile:  &profile,
 	})
 	if err != nil {
 		return fmt.Errorf("can't execute %w", err)
@@ -119,5 +149,88 @@ func statusBlocking(_ *cobra.Command, _ []string) error {
 		}
 	}
 
+	if resp.JSON200.Pauses != nil {
+		for _, pause := range *resp.JSON200.Pauses {
+			logBlockingPause(pause)
+		}
+	}
+
+	if resp.JSON200.ActiveSchedules != nil {
+		log.Log().Infof("active schedules: %s", strings.Join(*resp.JSON200.ActiveSchedules, "; "))
+	}
+
+	return nil
+}
+
+func logBlockingPause(pause api.ApiBlockingPause) {
+	target := "profile"
+	name := ""
+
+	if pause.Client != nil {
+		target, name = "client", *pause.Client
+	} else if pause.Profile != nil {
+		name = *pause.Profile
+	}
+
+	groupNames := "all"
+	if pause.Groups != nil {
+		groupNames = strings.Join(*pause.Groups, "; ")
+	}
+
+	if pause.AutoEnableInSec == nil || *pause.AutoEnableInSec == 0 {
+		log.Log().Infof("blocking paused for %s '%s', groups: %s", target, name, groupNames)
+	} else {
+		log.Log().Infof("blocking paused for %s '%s', groups: %s, for %d seconds",
+			target, name, groupNames, *pause.AutoEnableInSec)
+	}
+}
+
+func unblockRequests(_ *cobra.Command, _ []string) error {
+	client, err := api.NewClientWithResponses(apiURL())
+	if err != nil {
+		return fmt.Errorf("can't create client: %w", err)
+	}
+
+	resp, err := client.UnblockRequestsWithResponse(context.Background())
+	if err != nil {
+		return fmt.Errorf("can't execute %w", err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("response NOK, Status: %s", resp.Status())
+	}
+
+	if resp.JSON200 == nil || len(*resp.JSON200) == 0 {
+		log.Log().Info("no unblock requests")
+
+		return nil
+	}
+
+	for _, request := range *resp.JSON200 {
+		comment := ""
+		if request.Comment != nil {
+			comment = *request.Comment
+		}
+
+		log.Log().Infof("%s: client '%s' requested '%s' at %s, reason: %s, comment: %s", request.Id,
+			request.Client, request.Domain, request.RequestedAt.Format(time.DateTime), request.Reason, comment)
+	}
+
 	return nil
 }
+
+func dismissUnblockRequest(_ *cobra.Command, args []string) error {
+	client, err := api.NewClientWithResponses(apiURL())
+	if err != nil {
+		return fmt.Errorf("can't create client: %w", err)
+	}
+
+	resp, err := client.DismissUnblockRequestWithResponse(context.Background(), &api.DismissUnblockRequestParams{
+		Id: args[0],
+	})
+	if err != nil {
+		return fmt.Errorf("can't execute %w", err)
+	}
+
+	return printOkOrError(resp, string(resp.Body))
+}
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/miekg/dns"
+	"github.com/sirupsen/logrus"
+)
+
+// Authoritative configuration of the zones bGuard is authoritative for
+type Authoritative struct {
+	Zones    map[string]AuthoritativeZone `yaml:"zones"`
+	TSIGKeys map[string]TSIGKey           `yaml:"tsigKeys"`
+}
+
+// AuthoritativeZone configuration of a single zone
+type AuthoritativeZone struct {
+	// zone file path
+	File string `yaml:"file"`
+	// inline zone file content
+	Records string `yaml:"records"`
+	// primary servers to transfer the zone from, makes the zone a secondary zone
+	Primaries []string `yaml:"primaries"`
+	// name of the TSIG key used for transfers and NOTIFY messages
+	TSIGKey string `yaml:"tsigKey"`
+	// IPs or CIDRs of secondaries which may transfer the zone
+	AllowTransfer []string `yaml:"allowTransfer"`
+	// names of the TSIG keys which may send dynamic updates
+	UpdateKeys []string `yaml:"updateKeys"`
+	// journal file path for dynamic updates, the zone file path with ".jnl" if empty
+	Journal string `yaml:"journal"`
+}
+
+// TSIGKey is a shared secret to authenticate DNS messages (RFC 8945)
+type TSIGKey struct {
+	// HMAC algorithm, hmac-sha256 if empty
+	Algorithm string `yaml:"algorithm"`
+	// base64 encoded secret
+	Secret string `yaml:"secret"`
+}
+
+// IsSecondary returns true if the zone is transferred from primary servers
+func (c *AuthoritativeZone) IsSecondary() bool {
+	return len(c.Primaries) != 0
+}
+
+// JournalPath returns the path of the journal for dynamic updates, or an empty string if there is none
+func (c *AuthoritativeZone) JournalPath() string {
+	if c.Journal == "" && c.File != "" {
+		return c.File + ".jnl"
+	}
+
+	return c.Journal
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *Authoritative) IsEnabled() bool {
+	return len(c.Zones) != 0
+}
+
+// TSIGSecrets returns the base64 encoded secrets by canonical key name
+func (c *Authoritative) TSIGSecrets() map[string]string {
+	secrets := make(map[string]string, len(c.TSIGKeys))
+
+	for name, key := range c.TSIGKeys {
+		secrets[dns.CanonicalName(name)] = key.Secret
+	}
+
+	return secrets
+}
+
+// TSIGAlgorithm returns the canonical algorithm name of the key
+func (c *TSIGKey) TSIGAlgorithm() (string, error) {
+	if c.Algorithm == "" {
+		return dns.HmacSHA256, nil
+	}
+
+	algorithm := dns.CanonicalName(c.Algorithm)
+
+	switch algorithm {
+	case dns.HmacSHA1, dns.HmacSHA224, dns.HmacSHA256, dns.HmacSHA384, dns.HmacSHA512:
+		return algorithm, nil
+	}
+
+	return "", fmt.Errorf("unsupported TSIG algorithm '%s'", c.Algorithm)
+}
+
+func (c *Authoritative) validate(logger *logrus.Entry) {
+	for name, key := range c.TSIGKeys {
+		if _, err := key.TSIGAlgorithm(); err != nil {
+			logger.Warnf("authoritative.tsigKeys.%s: %s", name, err)
+		}
+
+		if _, err := base64.StdEncoding.DecodeString(key.Secret); err != nil || key.Secret == "" {
+			logger.Warnf("authoritative.tsigKeys.%s: secret must be base64 encoded", name)
+		}
+	}
+
+	for name, zone := range c.Zones {
+		for _, key := range append([]string{zone.TSIGKey}, zone.UpdateKeys...) {
+			if _, ok := c.TSIGKeys[key]; !ok && key != "" {
+				logger.Warnf("authoritative.zones.%s: unknown TSIG key '%s'", name, key)
+			}
+		}
+
+		if len(zone.UpdateKeys) > 0 && zone.JournalPath() == "" {
+			logger.Warnf("authoritative.zones.%s: dynamic updates are lost on restart without a journal", name)
+		}
+	}
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *Authoritative) LogConfig(logger *logrus.Entry) {
+	names := make([]string, 0, len(c.Zones))
+
+	for name := range c.Zones {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	logger.Info("zones:")
+
+	for _, name := range names {
+		zone := c.Zones[name]
+
+		logger.Infof("  %s:", name)
+
+		switch {
+		case zone.IsSecondary():
+			logger.Infof("    primaries = %s", strings.Join(zone.Primaries, ", "))
+		case zone.File != "":
+			logger.Infof("    file = %s", zone.File)
+		default:
+			logger.Info("    records = inline")
+		}
+
+		if zone.TSIGKey != "" {
+			logger.Infof("    tsigKey = %s", zone.TSIGKey)
+		}
+
+		if len(zone.AllowTransfer) > 0 {
+			logger.Infof("    allowTransfer = %v", zone.AllowTransfer)
+		}
+
+		if len(zone.UpdateKeys) > 0 {
+			logger.Infof("    updateKeys = %v", zone.UpdateKeys)
+
+			if journal := zone.JournalPath(); journal != "" {
+				logger.Infof("    journal = %s", journal)
+			}
+		}
+	}
+
+	if len(c.TSIGKeys) > 0 {
+		logger.Info("tsigKeys:")
+
+		keyNames := make([]string, 0, len(c.TSIGKeys))
+
+		for name := range c.TSIGKeys {
+			keyNames = append(keyNames, name)
+		}
+
+		sort.Strings(keyNames)
+
+		for _, name := range keyNames {
+			key := c.TSIGKeys[name]
+			algorithm, _ := key.TSIGAlgorithm()
+
+			logger.Infof("  %s: algorithm = %s, secret = %s", name, strings.TrimSuffix(algorithm, "."), secretObfuscator)
+		}
+	}
+}
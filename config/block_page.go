@@ -0,0 +1,50 @@
+package config
+
+import (
+	"net"
+
+	"github.com/sirupsen/logrus"
+)
+
+// BlockPage configuration of the page served for blocked domains
+type BlockPage struct {
+	// IPs of the HTTP(S) listener, blocked A/AAAA queries are answered with them
+	IPs []net.IP `yaml:"ips"`
+	// allow clients to request the unblocking of a domain from the page
+	UnblockRequests bool `yaml:"unblockRequests" default:"true"`
+	// reverse proxies in front of the HTTP(S) listener, whose X-Forwarded-For header is used as client IP
+	TrustedProxies []ClientAddress `yaml:"trustedProxies"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *BlockPage) IsEnabled() bool {
+	return len(c.IPs) != 0
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *BlockPage) LogConfig(logger *logrus.Entry) {
+	logger.Infof("IPs = %v", c.IPs)
+	logger.Infof("unblockRequests = %t", c.UnblockRequests)
+
+	if len(c.TrustedProxies) > 0 {
+		logger.Infof("trustedProxies = %s", c.TrustedProxies)
+	}
+}
+
+// IsTrustedProxy returns true if the IP is a trusted reverse proxy, whose X-Forwarded-For header is used
+func (c *BlockPage) IsTrustedProxy(ip net.IP) bool {
+	return containsIP(c.TrustedProxies, ip)
+}
+
+func (c *BlockPage) validate(logger *logrus.Entry, ports *Ports) {
+	if c.IsEnabled() && len(ports.HTTP) == 0 && len(ports.HTTPS) == 0 {
+		logger.Warn("blocking.blockPage: no HTTP or HTTPS port, the block page can't be served")
+	}
+
+	for _, proxy := range c.TrustedProxies {
+		if proxy.MAC != nil {
+			logger.Warnf("blocking.blockPage.trustedProxies: '%s' is a MAC address, only IPs, subnets and IP ranges are used",
+				proxy)
+		}
+	}
+}
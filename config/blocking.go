@@ -1,9 +1,13 @@
 package config
 
 import (
+	"slices"
+	"strings"
+
 	. "github.com/Abiji-2020/bGuard/config/migration"
 	"github.com/Abiji-2020/bGuard/log"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
 )
 
 // Blocking configuration for query blocking
@@ -13,7 +17,15 @@ type Blocking struct {
 	ClientGroupsBlock map[string][]string      `yaml:"clientGroupsBlock"`
 	BlockType         string                   `yaml:"blockType" default:"ZEROIP"`
 	BlockTTL          Duration                 `yaml:"blockTTL" default:"6h"`
-	Loading           SourceLoading            `yaml:"loading"`
+	// block types and TTLs of single denylist groups by group name
+	Groups  map[string]BlockingGroup `yaml:"groups"`
+	Loading SourceLoading            `yaml:"loading"`
+	// schedules turning denylist groups on or off by schedule name
+	Schedules map[string]BlockingSchedule `yaml:"schedules"`
+	BlockPage BlockPage                   `yaml:"blockPage"`
+	// path of the MaxMind or IPinfo database used to match AS entries of the denylists
+	ASNDatabase string `yaml:"asnDatabase"`
+	GeoIP       GeoIP  `yaml:"geoIP"`
 
 	// Deprecated options
 	Deprecated struct {
@@ -68,9 +80,33 @@ func (c *Blocking) LogConfig(logger *logrus.Entry) {
 		logger.Infof("blockTTL = %s", c.BlockTTL)
 	}
 
+	if len(c.Groups) != 0 {
+		logger.Info("groups:")
+		log.WithIndent(logger, "  ", c.logGroups)
+	}
+
+	if c.BlockPage.IsEnabled() {
+		logger.Info("blockPage:")
+		log.WithIndent(logger, "  ", c.BlockPage.LogConfig)
+	}
+
+	if c.ASNDatabase != "" {
+		logger.Infof("asnDatabase = %s", c.ASNDatabase)
+	}
+
+	if c.GeoIP.IsEnabled() {
+		logger.Info("geoIP:")
+		log.WithIndent(logger, "  ", c.GeoIP.LogConfig)
+	}
+
 	logger.Info("loading:")
 	log.WithIndent(logger, "  ", c.Loading.LogConfig)
 
+	if len(c.Schedules) != 0 {
+		logger.Info("schedules:")
+		log.WithIndent(logger, "  ", c.logSchedules)
+	}
+
 	logger.Info("denylists:")
 	log.WithIndent(logger, "  ", func(logger *logrus.Entry) {
 		c.logListGroups(logger, c.Denylists)
@@ -82,6 +118,83 @@ func (c *Blocking) LogConfig(logger *logrus.Entry) {
 	})
 }
 
+func (c *Blocking) logSchedules(logger *logrus.Entry) {
+	names := maps.Keys(c.Schedules)
+	slices.Sort(names)
+
+	for _, name := range names {
+		schedule := c.Schedules[name]
+
+		logger.Infof("%s:", name)
+		logger.Infof("  %s groups = %s", schedule.Action, strings.Join(schedule.Groups, ", "))
+
+		if len(schedule.Clients) != 0 {
+			logger.Infof("  clients = %s", strings.Join(schedule.Clients, ", "))
+		}
+
+		if len(schedule.Profiles) != 0 {
+			logger.Infof("  profiles = %s", strings.Join(schedule.Profiles, ", "))
+		}
+
+		logger.Infof("  timezone = %s", schedule.Timezone)
+		logger.Infof("  windows = %v", schedule.Windows)
+	}
+}
+
+func (c *Blocking) logGroups(logger *logrus.Entry) {
+	names := maps.Keys(c.Groups)
+	slices.Sort(names)
+
+	for _, name := range names {
+		group := c.Groups[name]
+
+		logger.Infof("%s:", name)
+
+		if group.BlockType != "" {
+			logger.Infof("  blockType = %s", group.BlockType)
+		}
+
+		if group.BlockTTL != nil {
+			logger.Infof("  blockTTL = %s", group.BlockTTL)
+		}
+	}
+}
+
+func (c *Blocking) validate(logger *logrus.Entry, profiles Profiles) {
+	for name := range c.Groups {
+		if _, ok := c.Denylists[name]; !ok {
+			logger.Warnf("blocking.groups.%s: unknown denylist group", name)
+		}
+	}
+
+	for name, sources := range c.Allowlists {
+		for _, source := range sources {
+			if source.Format == BytesSourceFormatRpz || source.Type == BytesSourceTypeAxfr {
+				logger.Warnf("blocking.allowlists.%s: response policy zone '%s' has no effect, only denylists support them",
+					name, source)
+			}
+		}
+	}
+
+	for name, schedule := range c.Schedules {
+		if len(schedule.Windows) == 0 {
+			logger.Warnf("blocking.schedules.%s: no windows, the schedule is never active", name)
+		}
+
+		for _, group := range schedule.Groups {
+			if _, ok := c.Denylists[group]; !ok {
+				logger.Warnf("blocking.schedules.%s: unknown denylist group '%s'", name, group)
+			}
+		}
+
+		for _, profile := range schedule.Profiles {
+			if _, ok := profiles[profile]; !ok {
+				logger.Warnf("blocking.schedules.%s: unknown profile '%s'", name, profile)
+			}
+		}
+	}
+}
+
 func (c *Blocking) logListGroups(logger *logrus.Entry, listGroups map[string][]BytesSource) {
 	for group, sources := range listGroups {
 		logger.Infof("%s:", group)
@@ -91,3 +204,11 @@ func (c *Blocking) logListGroups(logger *logrus.Entry, listGroups map[string][]B
 		}
 	}
 }
+
+// BlockingGroup settings of a single denylist group
+type BlockingGroup struct {
+	// replaces blocking.blockType
+	BlockType string `yaml:"blockType"`
+	// replaces blocking.blockTTL
+	BlockTTL *Duration `yaml:"blockTTL"`
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	// timezones must be available in minimal containers without zoneinfo
+	_ "time/tzdata"
+)
+
+const minutesPerDay = 24 * 60
+
+// ScheduleAction what a schedule does with its groups during its windows ENUM(
+// block // the groups are checked only during the windows
+// allow // the groups are not checked during the windows
+// )
+type ScheduleAction uint8
+
+// BlockingSchedule turns denylist groups on or off during recurring weekly windows
+type BlockingSchedule struct {
+	// groups of blocking.denylists
+	Groups []string       `yaml:"groups"`
+	Action ScheduleAction `yaml:"action"`
+	// client names (with wildcards) or IDs, IPs, CIDR subnets, IP ranges or MAC addresses
+	Clients []string `yaml:"clients"`
+	// client profiles, the schedule applies to all clients if neither clients nor profiles are set
+	Profiles []string         `yaml:"profiles"`
+	Timezone Timezone         `yaml:"timezone"`
+	Windows  []ScheduleWindow `yaml:"windows"`
+}
+
+// IsActive returns true if t is within one of the windows
+func (s *BlockingSchedule) IsActive(t time.Time) bool {
+	t = t.In(s.Timezone.Location())
+
+	for _, window := range s.Windows {
+		if window.contains(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ScheduleWindow is a recurring weekly time window.
+// A window ending before its start ends on the next day, a window ending at its start lasts 24 hours.
+type ScheduleWindow struct {
+	// days the window starts on, every day if empty
+	Days []Weekday `yaml:"days"`
+	From TimeOfDay `yaml:"from"`
+	To   TimeOfDay `yaml:"to"`
+}
+
+func (w *ScheduleWindow) contains(t time.Time) bool {
+	minute := TimeOfDay(t.Hour()*60 + t.Minute())
+	overnight := w.To <= w.From
+
+	// started today
+	if w.startsOn(t.Weekday()) && minute >= w.From && (overnight || minute < w.To) {
+		return true
+	}
+
+	// started yesterday
+	yesterday := (t.Weekday() + 6) % 7 //nolint:mnd
+
+	return overnight && w.startsOn(yesterday) && minute < w.To
+}
+
+func (w *ScheduleWindow) startsOn(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+
+	for _, d := range w.Days {
+		if time.Weekday(d) == day {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String implements `fmt.Stringer`.
+func (w ScheduleWindow) String() string {
+	days := "daily"
+
+	if len(w.Days) != 0 {
+		names := make([]string, 0, len(w.Days))
+
+		for _, d := range w.Days {
+			names = append(names, d.String())
+		}
+
+		days = strings.Join(names, ",")
+	}
+
+	return fmt.Sprintf("%s %s-%s", days, w.From, w.To)
+}
+
+// Weekday is a day of the week, written as "mon" or "monday"
+type Weekday time.Weekday
+
+// UnmarshalText implements `encoding.TextUnmarshaler`.
+func (d *Weekday) UnmarshalText(data []byte) error {
+	text := strings.ToLower(strings.TrimSpace(string(data)))
+
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+
+		if text == name || text == name[:3] {
+			*d = Weekday(day)
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid weekday '%s'", text)
+}
+
+// String implements `fmt.Stringer`.
+func (d Weekday) String() string {
+	return strings.ToLower(time.Weekday(d).String()[:3])
+}
+
+// TimeOfDay is the number of minutes since midnight, written as "HH:MM"
+type TimeOfDay uint16
+
+// UnmarshalText implements `encoding.TextUnmarshaler`.
+func (t *TimeOfDay) UnmarshalText(data []byte) error {
+	text := strings.TrimSpace(string(data))
+
+	var hour, minute uint16
+
+	if _, err := fmt.Sscanf(text, "%d:%d", &hour, &minute); err != nil {
+		return fmt.Errorf("invalid time of day '%s', expected HH:MM: %w", text, err)
+	}
+
+	value := hour*60 + minute //nolint:mnd
+	if minute >= 60 || value > minutesPerDay {
+		return fmt.Errorf("invalid time of day '%s'", text)
+	}
+
+	*t = TimeOfDay(value % minutesPerDay)
+
+	return nil
+}
+
+// String implements `fmt.Stringer`.
+func (t TimeOfDay) String() string {
+	return fmt.Sprintf("%02d:%02d", t/60, t%60) //nolint:mnd
+}
+
+// Timezone is an IANA time zone name like "Europe/Berlin", local time if empty
+type Timezone struct {
+	location *time.Location
+}
+
+// UnmarshalText implements `encoding.TextUnmarshaler`.
+func (z *Timezone) UnmarshalText(data []byte) error {
+	name := strings.TrimSpace(string(data))
+	if name == "" {
+		*z = Timezone{}
+
+		return nil
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("invalid timezone: %w", err)
+	}
+
+	z.location = location
+
+	return nil
+}
+
+// Location returns the location of the time zone
+func (z Timezone) Location() *time.Location {
+	if z.location == nil {
+		return time.Local
+	}
+
+	return z.location
+}
+
+// String implements `fmt.Stringer`.
+func (z Timezone) String() string {
+	return z.Location().String()
+}
@@ -3,6 +3,7 @@ package config
 
 import (
 	"fmt"
+	"path"
 	"strings"
 )
 
@@ -10,16 +11,40 @@ const maxTextSourceDisplayLen = 12
 
 // var BytesSourceNone = BytesSource{}
 
+// BytesSourceCompression supported BytesSource compressions. ENUM(
+// auto // Detected from the Content-Type or the magic bytes.
+// none // Not compressed.
+// gzip // gzip.
+// zstd // Zstandard.
+// xz   // xz.
+// zip  // zip archive.
+// )
+type BytesSourceCompression uint16
+
+// BytesSourceFormat supported BytesSource formats. ENUM(
+// hosts   // Hosts file, domain, wildcard, regex or adblock list.
+// rpz     // Response policy zone.
+// dnsmasq // dnsmasq address=/domain/ and server=/domain/ lines.
+// unbound // unbound local-zone and local-data statements.
+// regex   // Regex list like the ones of Pi-hole, one regular expression per line.
+// )
+type BytesSourceFormat uint16
+
 // BytesSourceType supported BytesSource types. ENUM(
 // text=1 // Inline YAML block.
 // http   // HTTP(S).
 // file   // Local file.
+// axfr   // Zone transfer of an RPZ zone.
 // )
 type BytesSourceType uint16
 
 type BytesSource struct {
-	Type BytesSourceType
-	From string
+	Type        BytesSourceType
+	From        string
+	Format      BytesSourceFormat
+	Compression BytesSourceCompression
+	// Members are the path patterns of the zip archive members to read, all files if empty
+	Members []string
 }
 
 func (s BytesSource) String() string {
@@ -33,6 +58,9 @@ func (s BytesSource) String() string {
 	case BytesSourceTypeFile:
 		return fmt.Sprintf("file://%s", s.From)
 
+	case BytesSourceTypeAxfr:
+		return fmt.Sprintf("axfr://%s", s.From)
+
 	default:
 		return fmt.Sprintf("unknown source (%s: %s)", s.Type, s.From)
 	}
@@ -70,6 +98,10 @@ func (s *BytesSource) UnmarshalText(data []byte) error {
 	case strings.HasPrefix(source, "http"):
 		*s = BytesSource{Type: BytesSourceTypeHttp, From: source}
 
+	// Zone transfer of an RPZ zone: axfr://host[:port]/zone
+	case strings.HasPrefix(source, "axfr://"):
+		*s = BytesSource{Type: BytesSourceTypeAxfr, From: strings.TrimPrefix(source, "axfr://"), Format: BytesSourceFormatRpz}
+
 	// Probably path to a local file
 	default:
 		*s = BytesSource{Type: BytesSourceTypeFile, From: strings.TrimPrefix(source, "file://")}
@@ -78,6 +110,52 @@ func (s *BytesSource) UnmarshalText(data []byte) error {
 	return nil
 }
 
+// UnmarshalYAML creates BytesSource from YAML: the source as string or an object with source, format,
+// compression and zip archive members
+func (s *BytesSource) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var source string
+	if err := unmarshal(&source); err == nil {
+		return s.UnmarshalText([]byte(source))
+	}
+
+	var c struct {
+		Source      string                 `yaml:"source"`
+		Format      BytesSourceFormat      `yaml:"format"`
+		Compression BytesSourceCompression `yaml:"compression"`
+		Members     []string               `yaml:"members"`
+	}
+
+	if err := unmarshal(&c); err != nil {
+		return err
+	}
+
+	if err := s.UnmarshalText([]byte(c.Source)); err != nil {
+		return err
+	}
+
+	if c.Format != BytesSourceFormatHosts {
+		s.Format = c.Format
+	}
+
+	s.Compression = c.Compression
+
+	if len(c.Members) != 0 {
+		if c.Compression != BytesSourceCompressionAuto && c.Compression != BytesSourceCompressionZip {
+			return fmt.Errorf("members of source '%s' require zip compression", c.Source)
+		}
+
+		for _, member := range c.Members {
+			if _, err := path.Match(member, ""); err != nil {
+				return fmt.Errorf("invalid member pattern '%s': %w", member, err)
+			}
+		}
+
+		s.Members = c.Members
+	}
+
+	return nil
+}
+
 func newBytesSource(source string) BytesSource {
 	var res BytesSource
 
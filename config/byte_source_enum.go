@@ -5,6 +5,219 @@ import (
 	"strings"
 )
 
+const (
+	// BytesSourceCompressionAuto is a BytesSourceCompression of type Auto.
+	// Detected from the Content-Type or the magic bytes.
+	BytesSourceCompressionAuto BytesSourceCompression = iota
+	// BytesSourceCompressionNone is a BytesSourceCompression of type None.
+	// Not compressed.
+	BytesSourceCompressionNone
+	// BytesSourceCompressionGzip is a BytesSourceCompression of type Gzip.
+	// gzip.
+	BytesSourceCompressionGzip
+	// BytesSourceCompressionZstd is a BytesSourceCompression of type Zstd.
+	// Zstandard.
+	BytesSourceCompressionZstd
+	// BytesSourceCompressionXz is a BytesSourceCompression of type Xz.
+	// xz.
+	BytesSourceCompressionXz
+	// BytesSourceCompressionZip is a BytesSourceCompression of type Zip.
+	// zip archive.
+	BytesSourceCompressionZip
+)
+
+var ErrInvalidBytesSourceCompression = fmt.Errorf("not a valid BytesSourceCompression, try [%s]", strings.Join(_BytesSourceCompressionNames, ", "))
+
+const _BytesSourceCompressionName = "autononegzipzstdxzzip"
+
+var _BytesSourceCompressionNames = []string{
+	_BytesSourceCompressionName[0:4],
+	_BytesSourceCompressionName[4:8],
+	_BytesSourceCompressionName[8:12],
+	_BytesSourceCompressionName[12:16],
+	_BytesSourceCompressionName[16:18],
+	_BytesSourceCompressionName[18:21],
+}
+
+// BytesSourceCompressionNames returns a list of possible string values of BytesSourceCompression.
+func BytesSourceCompressionNames() []string {
+	tmp := make([]string, len(_BytesSourceCompressionNames))
+	copy(tmp, _BytesSourceCompressionNames)
+	return tmp
+}
+
+// BytesSourceCompressionValues returns a list of the values for BytesSourceCompression
+func BytesSourceCompressionValues() []BytesSourceCompression {
+	return []BytesSourceCompression{
+		BytesSourceCompressionAuto,
+		BytesSourceCompressionNone,
+		BytesSourceCompressionGzip,
+		BytesSourceCompressionZstd,
+		BytesSourceCompressionXz,
+		BytesSourceCompressionZip,
+	}
+}
+
+var _BytesSourceCompressionMap = map[BytesSourceCompression]string{
+	BytesSourceCompressionAuto: _BytesSourceCompressionName[0:4],
+	BytesSourceCompressionNone: _BytesSourceCompressionName[4:8],
+	BytesSourceCompressionGzip: _BytesSourceCompressionName[8:12],
+	BytesSourceCompressionZstd: _BytesSourceCompressionName[12:16],
+	BytesSourceCompressionXz:   _BytesSourceCompressionName[16:18],
+	BytesSourceCompressionZip:  _BytesSourceCompressionName[18:21],
+}
+
+// String implements the Stringer interface.
+func (x BytesSourceCompression) String() string {
+	if str, ok := _BytesSourceCompressionMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("BytesSourceCompression(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x BytesSourceCompression) IsValid() bool {
+	_, ok := _BytesSourceCompressionMap[x]
+	return ok
+}
+
+var _BytesSourceCompressionValue = map[string]BytesSourceCompression{
+	_BytesSourceCompressionName[0:4]:   BytesSourceCompressionAuto,
+	_BytesSourceCompressionName[4:8]:   BytesSourceCompressionNone,
+	_BytesSourceCompressionName[8:12]:  BytesSourceCompressionGzip,
+	_BytesSourceCompressionName[12:16]: BytesSourceCompressionZstd,
+	_BytesSourceCompressionName[16:18]: BytesSourceCompressionXz,
+	_BytesSourceCompressionName[18:21]: BytesSourceCompressionZip,
+}
+
+// ParseBytesSourceCompression attempts to convert a string to a BytesSourceCompression.
+func ParseBytesSourceCompression(name string) (BytesSourceCompression, error) {
+	if x, ok := _BytesSourceCompressionValue[name]; ok {
+		return x, nil
+	}
+	return BytesSourceCompression(0), fmt.Errorf("%s is %w", name, ErrInvalidBytesSourceCompression)
+}
+
+// MarshalText implements the text marshaller method.
+func (x BytesSourceCompression) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *BytesSourceCompression) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseBytesSourceCompression(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+const (
+	// BytesSourceFormatHosts is a BytesSourceFormat of type Hosts.
+	// Hosts file, domain, wildcard, regex or adblock list.
+	BytesSourceFormatHosts BytesSourceFormat = iota
+	// BytesSourceFormatRpz is a BytesSourceFormat of type Rpz.
+	// Response policy zone.
+	BytesSourceFormatRpz
+	// BytesSourceFormatDnsmasq is a BytesSourceFormat of type Dnsmasq.
+	// dnsmasq address=/domain/ and server=/domain/ lines.
+	BytesSourceFormatDnsmasq
+	// BytesSourceFormatUnbound is a BytesSourceFormat of type Unbound.
+	// unbound local-zone and local-data statements.
+	BytesSourceFormatUnbound
+	// BytesSourceFormatRegex is a BytesSourceFormat of type Regex.
+	// Regex list like the ones of Pi-hole, one regular expression per line.
+	BytesSourceFormatRegex
+)
+
+var ErrInvalidBytesSourceFormat = fmt.Errorf("not a valid BytesSourceFormat, try [%s]", strings.Join(_BytesSourceFormatNames, ", "))
+
+const _BytesSourceFormatName = "hostsrpzdnsmasqunboundregex"
+
+var _BytesSourceFormatNames = []string{
+	_BytesSourceFormatName[0:5],
+	_BytesSourceFormatName[5:8],
+	_BytesSourceFormatName[8:15],
+	_BytesSourceFormatName[15:22],
+	_BytesSourceFormatName[22:27],
+}
+
+// BytesSourceFormatNames returns a list of possible string values of BytesSourceFormat.
+func BytesSourceFormatNames() []string {
+	tmp := make([]string, len(_BytesSourceFormatNames))
+	copy(tmp, _BytesSourceFormatNames)
+	return tmp
+}
+
+// BytesSourceFormatValues returns a list of the values for BytesSourceFormat
+func BytesSourceFormatValues() []BytesSourceFormat {
+	return []BytesSourceFormat{
+		BytesSourceFormatHosts,
+		BytesSourceFormatRpz,
+		BytesSourceFormatDnsmasq,
+		BytesSourceFormatUnbound,
+		BytesSourceFormatRegex,
+	}
+}
+
+var _BytesSourceFormatMap = map[BytesSourceFormat]string{
+	BytesSourceFormatHosts:   _BytesSourceFormatName[0:5],
+	BytesSourceFormatRpz:     _BytesSourceFormatName[5:8],
+	BytesSourceFormatDnsmasq: _BytesSourceFormatName[8:15],
+	BytesSourceFormatUnbound: _BytesSourceFormatName[15:22],
+	BytesSourceFormatRegex:   _BytesSourceFormatName[22:27],
+}
+
+// String implements the Stringer interface.
+func (x BytesSourceFormat) String() string {
+	if str, ok := _BytesSourceFormatMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("BytesSourceFormat(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x BytesSourceFormat) IsValid() bool {
+	_, ok := _BytesSourceFormatMap[x]
+	return ok
+}
+
+var _BytesSourceFormatValue = map[string]BytesSourceFormat{
+	_BytesSourceFormatName[0:5]:   BytesSourceFormatHosts,
+	_BytesSourceFormatName[5:8]:   BytesSourceFormatRpz,
+	_BytesSourceFormatName[8:15]:  BytesSourceFormatDnsmasq,
+	_BytesSourceFormatName[15:22]: BytesSourceFormatUnbound,
+	_BytesSourceFormatName[22:27]: BytesSourceFormatRegex,
+}
+
+// ParseBytesSourceFormat attempts to convert a string to a BytesSourceFormat.
+func ParseBytesSourceFormat(name string) (BytesSourceFormat, error) {
+	if x, ok := _BytesSourceFormatValue[name]; ok {
+		return x, nil
+	}
+	return BytesSourceFormat(0), fmt.Errorf("%s is %w", name, ErrInvalidBytesSourceFormat)
+}
+
+// MarshalText implements the text marshaller method.
+func (x BytesSourceFormat) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *BytesSourceFormat) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseBytesSourceFormat(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
 const (
 	// BytesSourceTypeText is a BytesSourceType of type Text.
 	// Inline YAML block.
@@ -15,16 +228,20 @@ const (
 	// BytesSourceTypeFile is a BytesSourceType of type File.
 	// Local file.
 	BytesSourceTypeFile
+	// BytesSourceTypeAxfr is a BytesSourceType of type Axfr.
+	// Zone transfer of an RPZ zone.
+	BytesSourceTypeAxfr
 )
 
 var ErrInvalidBytesSourceType = fmt.Errorf("not a valid BytesSourceType, try [%s]", strings.Join(_BytesSourceTypeNames, ", "))
 
-const _BytesSourceTypeName = "texthttpfile"
+const _BytesSourceTypeName = "texthttpfileaxfr"
 
 var _BytesSourceTypeNames = []string{
 	_BytesSourceTypeName[0:4],
 	_BytesSourceTypeName[4:8],
 	_BytesSourceTypeName[8:12],
+	_BytesSourceTypeName[12:16],
 }
 
 // BytesSourceTypeNames returns a list of possible string values of BytesSourceType.
@@ -40,6 +257,7 @@ func BytesSourceTypeValues() []BytesSourceType {
 		BytesSourceTypeText,
 		BytesSourceTypeHttp,
 		BytesSourceTypeFile,
+		BytesSourceTypeAxfr,
 	}
 }
 
@@ -47,6 +265,7 @@ var _BytesSourceTypeMap = map[BytesSourceType]string{
 	BytesSourceTypeText: _BytesSourceTypeName[0:4],
 	BytesSourceTypeHttp: _BytesSourceTypeName[4:8],
 	BytesSourceTypeFile: _BytesSourceTypeName[8:12],
+	BytesSourceTypeAxfr: _BytesSourceTypeName[12:16],
 }
 
 // String implements the Stringer interface.
@@ -65,9 +284,10 @@ func (x BytesSourceType) IsValid() bool {
 }
 
 var _BytesSourceTypeValue = map[string]BytesSourceType{
-	_BytesSourceTypeName[0:4]:  BytesSourceTypeText,
-	_BytesSourceTypeName[4:8]:  BytesSourceTypeHttp,
-	_BytesSourceTypeName[8:12]: BytesSourceTypeFile,
+	_BytesSourceTypeName[0:4]:   BytesSourceTypeText,
+	_BytesSourceTypeName[4:8]:   BytesSourceTypeHttp,
+	_BytesSourceTypeName[8:12]:  BytesSourceTypeFile,
+	_BytesSourceTypeName[12:16]: BytesSourceTypeAxfr,
 }
 
 // ParseBytesSourceType attempts to convert a string to a BytesSourceType.
@@ -1,21 +1,90 @@
 package config
 
 import (
+	"fmt"
 	"net"
+	"net/netip"
 
+	"github.com/Abiji-2020/bGuard/util"
 	"github.com/sirupsen/logrus"
 )
 
 // ClientLookup configuration for the client lookup
 type ClientLookup struct {
-	ClientnameIPMapping map[string][]net.IP `yaml:"clients"`
-	Upstream            Upstream            `yaml:"upstream"`
-	SingleNameOrder     []uint              `yaml:"singleNameOrder"`
+	ClientnameIPMapping map[string][]ClientAddress `yaml:"clients"`
+	Upstream            Upstream                   `yaml:"upstream"`
+	SingleNameOrder     []uint                     `yaml:"singleNameOrder"`
+	MACLookup           bool                       `yaml:"macLookup" default:"false"`
+	// forwarding DNS servers (IPs, subnets or IP ranges) trusted to add the client's MAC address as EDNS0 option
+	MACForwarders []ClientAddress `yaml:"macForwarders"`
+}
+
+// ClientAddress is the MAC address of a client, or its IP address, subnet (CIDR) or IP range ("<first>-<last>")
+type ClientAddress struct {
+	MAC      net.HardwareAddr
+	Prefixes []netip.Prefix
+
+	text string
+}
+
+// UnmarshalText implements `encoding.TextUnmarshaler`.
+func (a *ClientAddress) UnmarshalText(data []byte) error {
+	text := string(data)
+
+	if prefixes, ok := util.ParseIPPrefixes(text); ok {
+		*a = ClientAddress{Prefixes: prefixes, text: text}
+
+		return nil
+	}
+
+	if mac, err := net.ParseMAC(text); err == nil {
+		*a = ClientAddress{MAC: mac, text: mac.String()}
+
+		return nil
+	}
+
+	return fmt.Errorf("invalid client address '%s', must be an IP or MAC address, a CIDR subnet or an IP range", data)
+}
+
+// String implements `fmt.Stringer`.
+func (a ClientAddress) String() string {
+	return a.text
+}
+
+// IsMACForwarder returns true if the IP is a trusted forwarder, whose EDNS0 option with the client's MAC address is used
+func (c *ClientLookup) IsMACForwarder(ip net.IP) bool {
+	return containsIP(c.MACForwarders, ip)
+}
+
+// containsIP returns true if one of the IPs, subnets or IP ranges of the addresses contains the IP
+func containsIP(addresses []ClientAddress, ip net.IP) bool {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+
+	for _, address := range addresses {
+		for _, prefix := range address.Prefixes {
+			if prefix.Contains(addr.Unmap()) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (c *ClientLookup) validate(logger *logrus.Entry) {
+	for _, forwarder := range c.MACForwarders {
+		if forwarder.MAC != nil {
+			logger.Warnf("clientLookup.macForwarders: '%s' is a MAC address, only IPs, subnets and IP ranges are used", forwarder)
+		}
+	}
 }
 
 // IsEnabled implements `config.Configurable`.
 func (c *ClientLookup) IsEnabled() bool {
-	return !c.Upstream.IsDefault() || len(c.ClientnameIPMapping) != 0
+	return !c.Upstream.IsDefault() || len(c.ClientnameIPMapping) != 0 || c.MACLookup
 }
 
 // LogConfig implements `config.Configurable`.
@@ -25,6 +94,11 @@ func (c *ClientLookup) LogConfig(logger *logrus.Entry) {
 	}
 
 	logger.Infof("singleNameOrder = %v", c.SingleNameOrder)
+	logger.Infof("macLookup = %t", c.MACLookup)
+
+	if c.MACLookup && len(c.MACForwarders) > 0 {
+		logger.Infof("macForwarders = %s", c.MACForwarders)
+	}
 
 	if len(c.ClientnameIPMapping) > 0 {
 		logger.Infof("client IP mapping:")
@@ -45,6 +45,7 @@ type Configurable interface {
 // tcp+udp // TCP and UDP protocols
 // tcp-tls // TCP-TLS protocol
 // https // HTTPS protocol
+// recursive // Iterative resolution starting at the root servers
 // )
 type NetProtocol uint16
 
@@ -147,6 +148,12 @@ func (s InitStrategy) Do(ctx context.Context, init func(context.Context) error,
 	return nil
 }
 
+// RebindingAction what to do with answers pointing to private addresses ENUM(
+// drop // remove the offending records from the answer
+// nxDomain // answer with NXDOMAIN
+// )
+type RebindingAction uint8
+
 // QueryLogField data field to be logged
 // ENUM(clientIP,clientName,responseReason,responseAnswer,question,duration)
 type QueryLogField string
@@ -232,11 +239,18 @@ type Config struct {
 	KeyFile          string              `yaml:"keyFile"`
 	BootstrapDNS     BootstrapDNS        `yaml:"bootstrapDns"`
 	HostsFile        HostsFile           `yaml:"hostsFile"`
+	DHCPLeases       DHCPLeases          `yaml:"dhcpLeases"`
 	FQDNOnly         FQDNOnly            `yaml:"fqdnOnly"`
 	Filtering        Filtering           `yaml:"filtering"`
 	EDE              EDE                 `yaml:"ede"`
 	ECS              ECS                 `yaml:"ecs"`
 	SUDN             SUDN                `yaml:"specialUseDomains"`
+	DNS64            DNS64               `yaml:"dns64"`
+	Rebinding        RebindingProtection `yaml:"rebindingProtection"`
+	DNSCookies       DNSCookies          `yaml:"dnsCookies"`
+	Authoritative    Authoritative       `yaml:"authoritative"`
+	Profiles         Profiles            `yaml:"profiles"`
+	SafeSearch       SafeSearch          `yaml:"safeSearch"`
 
 	// Deprecated options
 	Deprecated struct {
@@ -401,12 +415,17 @@ type Downloader struct {
 	Timeout  Duration `yaml:"timeout" default:"5s"`
 	Attempts uint     `yaml:"attempts" default:"3"`
 	Cooldown Duration `yaml:"cooldown" default:"500ms"`
+	CacheDir string   `yaml:"cacheDir"`
 }
 
 func (c *Downloader) LogConfig(logger *logrus.Entry) {
 	logger.Infof("timeout = %s", c.Timeout)
 	logger.Infof("attempts = %d", c.Attempts)
 	logger.Debugf("cooldown = %s", c.Cooldown)
+
+	if c.CacheDir != "" {
+		logger.Infof("cacheDir = %s", c.CacheDir)
+	}
 }
 
 func WithDefaults[T any]() (T, error) {
@@ -592,6 +611,14 @@ func (cfg *Config) migrate(logger *logrus.Entry) bool {
 func (cfg *Config) validate(logger *logrus.Entry) {
 	cfg.MinTLSServeVer.validate(logger)
 	cfg.Upstreams.validate(logger)
+	cfg.ClientLookup.validate(logger)
+	cfg.DNSCookies.validate(logger)
+	cfg.Authoritative.validate(logger)
+	cfg.Blocking.validate(logger, cfg.Profiles)
+	cfg.Blocking.BlockPage.validate(logger, &cfg.Ports)
+	cfg.Blocking.GeoIP.validate(logger, cfg.Blocking.Denylists)
+	cfg.DHCPLeases.validate(logger)
+	cfg.Profiles.validate(logger, cfg)
 }
 
 // ConvertPort converts string representation into a valid port (0 - 65535)
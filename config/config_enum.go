@@ -183,6 +183,102 @@ func (x *InitStrategy) UnmarshalText(text []byte) error {
 	return nil
 }
 
+const (
+	// LeaseFormatDnsmasq is a LeaseFormat of type Dnsmasq.
+	// dnsmasq.leases file
+	LeaseFormatDnsmasq LeaseFormat = iota
+	// LeaseFormatIscDhcpd is a LeaseFormat of type IscDhcpd.
+	// ISC dhcpd.leases file
+	LeaseFormatIscDhcpd
+	// LeaseFormatKea is a LeaseFormat of type Kea.
+	// Kea memfile CSV lease file
+	LeaseFormatKea
+	// LeaseFormatOdhcpd is a LeaseFormat of type Odhcpd.
+	// OpenWrt odhcpd lease file
+	LeaseFormatOdhcpd
+)
+
+var ErrInvalidLeaseFormat = fmt.Errorf("not a valid LeaseFormat, try [%s]", strings.Join(_LeaseFormatNames, ", "))
+
+const _LeaseFormatName = "dnsmasqiscDhcpdkeaodhcpd"
+
+var _LeaseFormatNames = []string{
+	_LeaseFormatName[0:7],
+	_LeaseFormatName[7:15],
+	_LeaseFormatName[15:18],
+	_LeaseFormatName[18:24],
+}
+
+// LeaseFormatNames returns a list of possible string values of LeaseFormat.
+func LeaseFormatNames() []string {
+	tmp := make([]string, len(_LeaseFormatNames))
+	copy(tmp, _LeaseFormatNames)
+	return tmp
+}
+
+// LeaseFormatValues returns a list of the values for LeaseFormat
+func LeaseFormatValues() []LeaseFormat {
+	return []LeaseFormat{
+		LeaseFormatDnsmasq,
+		LeaseFormatIscDhcpd,
+		LeaseFormatKea,
+		LeaseFormatOdhcpd,
+	}
+}
+
+var _LeaseFormatMap = map[LeaseFormat]string{
+	LeaseFormatDnsmasq:  _LeaseFormatName[0:7],
+	LeaseFormatIscDhcpd: _LeaseFormatName[7:15],
+	LeaseFormatKea:      _LeaseFormatName[15:18],
+	LeaseFormatOdhcpd:   _LeaseFormatName[18:24],
+}
+
+// String implements the Stringer interface.
+func (x LeaseFormat) String() string {
+	if str, ok := _LeaseFormatMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("LeaseFormat(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x LeaseFormat) IsValid() bool {
+	_, ok := _LeaseFormatMap[x]
+	return ok
+}
+
+var _LeaseFormatValue = map[string]LeaseFormat{
+	_LeaseFormatName[0:7]:   LeaseFormatDnsmasq,
+	_LeaseFormatName[7:15]:  LeaseFormatIscDhcpd,
+	_LeaseFormatName[15:18]: LeaseFormatKea,
+	_LeaseFormatName[18:24]: LeaseFormatOdhcpd,
+}
+
+// ParseLeaseFormat attempts to convert a string to a LeaseFormat.
+func ParseLeaseFormat(name string) (LeaseFormat, error) {
+	if x, ok := _LeaseFormatValue[name]; ok {
+		return x, nil
+	}
+	return LeaseFormat(0), fmt.Errorf("%s is %w", name, ErrInvalidLeaseFormat)
+}
+
+// MarshalText implements the text marshaller method.
+func (x LeaseFormat) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *LeaseFormat) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseLeaseFormat(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
 const (
 	// NetProtocolTcpUdp is a NetProtocol of type Tcp+Udp.
 	// TCP and UDP protocols
@@ -193,16 +289,20 @@ const (
 	// NetProtocolHttps is a NetProtocol of type Https.
 	// HTTPS protocol
 	NetProtocolHttps
+	// NetProtocolRecursive is a NetProtocol of type Recursive.
+	// Iterative resolution starting at the root servers
+	NetProtocolRecursive
 )
 
 var ErrInvalidNetProtocol = fmt.Errorf("not a valid NetProtocol, try [%s]", strings.Join(_NetProtocolNames, ", "))
 
-const _NetProtocolName = "tcp+udptcp-tlshttps"
+const _NetProtocolName = "tcp+udptcp-tlshttpsrecursive"
 
 var _NetProtocolNames = []string{
 	_NetProtocolName[0:7],
 	_NetProtocolName[7:14],
 	_NetProtocolName[14:19],
+	_NetProtocolName[19:28],
 }
 
 // NetProtocolNames returns a list of possible string values of NetProtocol.
@@ -218,13 +318,15 @@ func NetProtocolValues() []NetProtocol {
 		NetProtocolTcpUdp,
 		NetProtocolTcpTls,
 		NetProtocolHttps,
+		NetProtocolRecursive,
 	}
 }
 
 var _NetProtocolMap = map[NetProtocol]string{
-	NetProtocolTcpUdp: _NetProtocolName[0:7],
-	NetProtocolTcpTls: _NetProtocolName[7:14],
-	NetProtocolHttps:  _NetProtocolName[14:19],
+	NetProtocolTcpUdp:    _NetProtocolName[0:7],
+	NetProtocolTcpTls:    _NetProtocolName[7:14],
+	NetProtocolHttps:     _NetProtocolName[14:19],
+	NetProtocolRecursive: _NetProtocolName[19:28],
 }
 
 // String implements the Stringer interface.
@@ -246,6 +348,7 @@ var _NetProtocolValue = map[string]NetProtocol{
 	_NetProtocolName[0:7]:   NetProtocolTcpUdp,
 	_NetProtocolName[7:14]:  NetProtocolTcpTls,
 	_NetProtocolName[14:19]: NetProtocolHttps,
+	_NetProtocolName[19:28]: NetProtocolRecursive,
 }
 
 // ParseNetProtocol attempts to convert a string to a NetProtocol.
@@ -478,6 +581,170 @@ func (x *QueryLogType) UnmarshalText(text []byte) error {
 	return nil
 }
 
+const (
+	// RebindingActionDrop is a RebindingAction of type Drop.
+	// remove the offending records from the answer
+	RebindingActionDrop RebindingAction = iota
+	// RebindingActionNxDomain is a RebindingAction of type NxDomain.
+	// answer with NXDOMAIN
+	RebindingActionNxDomain
+)
+
+var ErrInvalidRebindingAction = fmt.Errorf("not a valid RebindingAction, try [%s]", strings.Join(_RebindingActionNames, ", "))
+
+const _RebindingActionName = "dropnxDomain"
+
+var _RebindingActionNames = []string{
+	_RebindingActionName[0:4],
+	_RebindingActionName[4:12],
+}
+
+// RebindingActionNames returns a list of possible string values of RebindingAction.
+func RebindingActionNames() []string {
+	tmp := make([]string, len(_RebindingActionNames))
+	copy(tmp, _RebindingActionNames)
+	return tmp
+}
+
+// RebindingActionValues returns a list of the values for RebindingAction
+func RebindingActionValues() []RebindingAction {
+	return []RebindingAction{
+		RebindingActionDrop,
+		RebindingActionNxDomain,
+	}
+}
+
+var _RebindingActionMap = map[RebindingAction]string{
+	RebindingActionDrop:     _RebindingActionName[0:4],
+	RebindingActionNxDomain: _RebindingActionName[4:12],
+}
+
+// String implements the Stringer interface.
+func (x RebindingAction) String() string {
+	if str, ok := _RebindingActionMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("RebindingAction(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x RebindingAction) IsValid() bool {
+	_, ok := _RebindingActionMap[x]
+	return ok
+}
+
+var _RebindingActionValue = map[string]RebindingAction{
+	_RebindingActionName[0:4]:  RebindingActionDrop,
+	_RebindingActionName[4:12]: RebindingActionNxDomain,
+}
+
+// ParseRebindingAction attempts to convert a string to a RebindingAction.
+func ParseRebindingAction(name string) (RebindingAction, error) {
+	if x, ok := _RebindingActionValue[name]; ok {
+		return x, nil
+	}
+	return RebindingAction(0), fmt.Errorf("%s is %w", name, ErrInvalidRebindingAction)
+}
+
+// MarshalText implements the text marshaller method.
+func (x RebindingAction) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *RebindingAction) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseRebindingAction(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+const (
+	// ScheduleActionBlock is a ScheduleAction of type Block.
+	// the groups are checked only during the windows
+	ScheduleActionBlock ScheduleAction = iota
+	// ScheduleActionAllow is a ScheduleAction of type Allow.
+	// the groups are not checked during the windows
+	ScheduleActionAllow
+)
+
+var ErrInvalidScheduleAction = fmt.Errorf("not a valid ScheduleAction, try [%s]", strings.Join(_ScheduleActionNames, ", "))
+
+const _ScheduleActionName = "blockallow"
+
+var _ScheduleActionNames = []string{
+	_ScheduleActionName[0:5],
+	_ScheduleActionName[5:10],
+}
+
+// ScheduleActionNames returns a list of possible string values of ScheduleAction.
+func ScheduleActionNames() []string {
+	tmp := make([]string, len(_ScheduleActionNames))
+	copy(tmp, _ScheduleActionNames)
+	return tmp
+}
+
+// ScheduleActionValues returns a list of the values for ScheduleAction
+func ScheduleActionValues() []ScheduleAction {
+	return []ScheduleAction{
+		ScheduleActionBlock,
+		ScheduleActionAllow,
+	}
+}
+
+var _ScheduleActionMap = map[ScheduleAction]string{
+	ScheduleActionBlock: _ScheduleActionName[0:5],
+	ScheduleActionAllow: _ScheduleActionName[5:10],
+}
+
+// String implements the Stringer interface.
+func (x ScheduleAction) String() string {
+	if str, ok := _ScheduleActionMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("ScheduleAction(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x ScheduleAction) IsValid() bool {
+	_, ok := _ScheduleActionMap[x]
+	return ok
+}
+
+var _ScheduleActionValue = map[string]ScheduleAction{
+	_ScheduleActionName[0:5]:  ScheduleActionBlock,
+	_ScheduleActionName[5:10]: ScheduleActionAllow,
+}
+
+// ParseScheduleAction attempts to convert a string to a ScheduleAction.
+func ParseScheduleAction(name string) (ScheduleAction, error) {
+	if x, ok := _ScheduleActionValue[name]; ok {
+		return x, nil
+	}
+	return ScheduleAction(0), fmt.Errorf("%s is %w", name, ErrInvalidScheduleAction)
+}
+
+// MarshalText implements the text marshaller method.
+func (x ScheduleAction) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *ScheduleAction) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseScheduleAction(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
 const (
 	// TLSVersion10 is a TLSVersion of type 1.0.
 	TLSVersion10 TLSVersion = iota + 769
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LeaseFormat format of a DHCP lease file ENUM(
+// dnsmasq // dnsmasq.leases file
+// iscDhcpd // ISC dhcpd.leases file
+// kea // Kea memfile CSV lease file
+// odhcpd // OpenWrt odhcpd lease file
+// )
+type LeaseFormat uint8
+
+// DHCPLeases configuration of the DHCP lease files used for client names and local records
+type DHCPLeases struct {
+	Sources []LeaseSource `yaml:"sources"`
+	// domain of the records for the leased host names, no records are created if empty
+	Domain        string   `yaml:"domain"`
+	TTL           Duration `yaml:"ttl" default:"1m"`
+	CheckInterval Duration `yaml:"checkInterval" default:"10s"`
+}
+
+// LeaseSource is a DHCP lease file
+type LeaseSource struct {
+	Format LeaseFormat `yaml:"format"`
+	Path   string      `yaml:"path"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *DHCPLeases) IsEnabled() bool {
+	return len(c.Sources) != 0
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *DHCPLeases) LogConfig(logger *logrus.Entry) {
+	if c.Domain != "" {
+		logger.Infof("domain = %s", strings.Trim(c.Domain, "."))
+		logger.Infof("ttl = %s", c.TTL)
+	}
+
+	logger.Infof("checkInterval = %s", c.CheckInterval)
+
+	logger.Info("sources:")
+
+	for _, source := range c.Sources {
+		logger.Infof("  - %s: %s", source.Format, source.Path)
+	}
+}
+
+func (c *DHCPLeases) validate(logger *logrus.Entry) {
+	if c.IsEnabled() && !c.CheckInterval.IsAboveZero() {
+		logger.Warnf("dhcpLeases.checkInterval = %s: the lease files are only loaded at startup", c.CheckInterval)
+	}
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DNS64 configuration for the synthesis of AAAA records from A records (RFC 6147)
+type DNS64 struct {
+	Prefix         NAT64Prefix `yaml:"prefix" default:"64:ff9b::/96"`
+	Clients        []string    `yaml:"clients"`
+	ExcludeDomains []string    `yaml:"excludeDomains"`
+}
+
+// NAT64Prefix is the IPv6 prefix IPv4 addresses are embedded into (RFC 6052)
+type NAT64Prefix net.IPNet
+
+// UnmarshalText implements `encoding.TextUnmarshaler`.
+func (p *NAT64Prefix) UnmarshalText(data []byte) error {
+	_, ipNet, err := net.ParseCIDR(string(data))
+	if err != nil {
+		return fmt.Errorf("invalid NAT64 prefix: %w", err)
+	}
+
+	ones, bits := ipNet.Mask.Size()
+	if bits != net.IPv6len*8 || ipNet.IP.To4() != nil {
+		return fmt.Errorf("NAT64 prefix '%s' is not an IPv6 prefix", data)
+	}
+
+	switch ones {
+	case 32, 40, 48, 56, 64, 96: //nolint:mnd // RFC 6052 section 2.2
+	default:
+		return fmt.Errorf("NAT64 prefix '%s' must have a length of 32, 40, 48, 56, 64 or 96", data)
+	}
+
+	*p = NAT64Prefix(*ipNet)
+
+	return nil
+}
+
+// String implements `fmt.Stringer`.
+func (p *NAT64Prefix) String() string {
+	return (*net.IPNet)(p).String()
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *DNS64) IsEnabled() bool {
+	return len(c.Clients) != 0
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *DNS64) LogConfig(logger *logrus.Entry) {
+	logger.Infof("prefix = %s", &c.Prefix)
+
+	logger.Info("clients:")
+
+	for _, client := range c.Clients {
+		logger.Infof("  - %s", client)
+	}
+
+	if len(c.ExcludeDomains) > 0 {
+		logger.Info("excludeDomains:")
+
+		for _, domain := range c.ExcludeDomains {
+			logger.Infof("  - %s", domain)
+		}
+	}
+}
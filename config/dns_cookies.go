@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DNSCookies configuration for server cookies (RFC 7873, RFC 9018)
+type DNSCookies struct {
+	Enable         bool     `yaml:"enable" default:"false"`
+	SecretRotation Duration `yaml:"secretRotation" default:"1h"`
+	// queries per second and client, 0 means unlimited
+	RateLimit            uint `yaml:"rateLimit" default:"0"`
+	ValidCookieRateLimit uint `yaml:"validCookieRateLimit" default:"0"`
+}
+
+func (c *DNSCookies) validate(logger *logrus.Entry) {
+	defaults := mustDefault[DNSCookies]()
+
+	if !c.SecretRotation.IsAboveZero() {
+		logger.Warnf("dnsCookies.secretRotation <= 0, setting to %s", defaults.SecretRotation)
+		c.SecretRotation = defaults.SecretRotation
+	}
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *DNSCookies) IsEnabled() bool {
+	return c.Enable
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *DNSCookies) LogConfig(logger *logrus.Entry) {
+	logger.Infof("secretRotation = %s", c.SecretRotation)
+	logger.Infof("rateLimit = %s", formatRateLimit(c.RateLimit))
+	logger.Infof("validCookieRateLimit = %s", formatRateLimit(c.ValidCookieRateLimit))
+}
+
+func formatRateLimit(limit uint) string {
+	if limit == 0 {
+		return "unlimited"
+	}
+
+	return fmt.Sprintf("%d/s", limit)
+}
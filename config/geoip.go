@@ -0,0 +1,64 @@
+package config
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
+)
+
+// GeoIP configuration of the filtering of answers by the country of their IPs
+type GeoIP struct {
+	// path of the MMDB country database, e.g. GeoLite2-Country
+	Database string `yaml:"database"`
+	// country codes by group name, answers resolving into these countries are blocked
+	Block map[string][]string `yaml:"block"`
+	// country codes by group name, answers resolving into these countries are flagged in the query log and metrics
+	Flag map[string][]string `yaml:"flag"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *GeoIP) IsEnabled() bool {
+	return c.Database != ""
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *GeoIP) LogConfig(logger *logrus.Entry) {
+	logger.Infof("database = %s", c.Database)
+
+	logCountries := func(action string, countries map[string][]string) {
+		groups := maps.Keys(countries)
+		slices.Sort(groups)
+
+		for _, group := range groups {
+			logger.Infof("%s %s = %s", action, group, strings.Join(countries[group], ", "))
+		}
+	}
+
+	logCountries("block", c.Block)
+	logCountries("flag", c.Flag)
+}
+
+func (c *GeoIP) validate(logger *logrus.Entry, denylists map[string][]BytesSource) {
+	hasCountries := len(c.Block) != 0 || len(c.Flag) != 0
+
+	switch {
+	case c.IsEnabled() && !hasCountries:
+		logger.Warn("blocking.geoIP: no countries to block or flag, the database is not used")
+	case !c.IsEnabled() && hasCountries:
+		logger.Warn("blocking.geoIP: no database, the countries are ignored")
+	}
+
+	// the countries only apply to clients of these denylist groups
+	warnUnknownGroups := func(action string, countries map[string][]string) {
+		for group := range countries {
+			if _, ok := denylists[group]; !ok {
+				logger.Warnf("blocking.geoIP.%s: unknown denylist group '%s', its countries are never applied", action, group)
+			}
+		}
+	}
+
+	warnUnknownGroups("block", c.Block)
+	warnUnknownGroups("flag", c.Flag)
+}
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
+)
+
+// Profiles configuration of the client profiles by profile name
+type Profiles map[string]Profile
+
+// Profile bundles the policy of a set of clients.
+// Unset settings fall back to the global configuration.
+type Profile struct {
+	// client names (with wildcards) or IDs, IPs, CIDR subnets, IP ranges or MAC addresses
+	Clients []string `yaml:"clients"`
+	// listeners, whose requests use the profile regardless of the client
+	Listeners []ProfileListener `yaml:"listeners"`
+	// groups of blocking.denylists and blocking.allowlists, replaces blocking.clientGroupsBlock
+	BlockingGroups []string `yaml:"blockingGroups"`
+	// replaces blocking.blockType
+	BlockType string `yaml:"blockType"`
+	// group of upstreams.groups, replaces the group by client
+	UpstreamGroup string `yaml:"upstreamGroup"`
+	// replaces filtering.queryTypes
+	FilterQueryTypes QTypeSet `yaml:"filterQueryTypes"`
+	// replaces fqdnOnly.enable
+	FQDNOnly *bool `yaml:"fqdnOnly"`
+	// enables or disables safeSearch for the clients
+	SafeSearch *bool `yaml:"safeSearch"`
+}
+
+// ProfileListener is the address of a DNS or DoT listener, or the path of DoH requests
+type ProfileListener struct {
+	// nil for all IP addresses
+	IP   net.IP
+	Port uint16
+	Path string
+}
+
+// UnmarshalText implements `encoding.TextUnmarshaler`.
+func (l *ProfileListener) UnmarshalText(data []byte) error {
+	text := strings.TrimSpace(string(data))
+
+	if strings.HasPrefix(text, "/") {
+		*l = ProfileListener{Path: text}
+
+		return nil
+	}
+
+	host, port := "", text
+
+	if strings.Contains(text, ":") {
+		var err error
+
+		host, port, err = net.SplitHostPort(text)
+		if err != nil {
+			return fmt.Errorf("invalid listener '%s': %w", text, err)
+		}
+	}
+
+	p, err := ConvertPort(port)
+	if err != nil {
+		return fmt.Errorf("invalid listener port '%s': %w", text, err)
+	}
+
+	*l = ProfileListener{Port: p}
+
+	if host != "" {
+		if l.IP = net.ParseIP(host); l.IP == nil {
+			return fmt.Errorf("invalid listener IP '%s'", text)
+		}
+	}
+
+	return nil
+}
+
+// String implements `fmt.Stringer`.
+func (l ProfileListener) String() string {
+	switch {
+	case l.Path != "":
+		return l.Path
+	case l.IP != nil:
+		return net.JoinHostPort(l.IP.String(), strconv.Itoa(int(l.Port)))
+	default:
+		return fmt.Sprintf(":%d", l.Port)
+	}
+}
+
+// isBound returns true if one of the addresses binds the IP address and port of the listener.
+// A listener bound to all IP addresses only knows its port, not the IP address a request was sent to.
+func (l ProfileListener) isBound(addresses ListenConfig) bool {
+	for _, address := range addresses {
+		host, port, err := net.SplitHostPort(strings.TrimSpace(address))
+		if err != nil {
+			continue
+		}
+
+		if p, err := ConvertPort(port); err == nil && p == l.Port && l.IP.Equal(net.ParseIP(host)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *Profiles) IsEnabled() bool {
+	return len(*c) != 0
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *Profiles) LogConfig(logger *logrus.Entry) {
+	names := maps.Keys(*c)
+	slices.Sort(names)
+
+	for _, name := range names {
+		profile := (*c)[name]
+
+		logger.Infof("%s:", name)
+		logger.Infof("  clients = %s", strings.Join(profile.Clients, ", "))
+
+		if len(profile.Listeners) != 0 {
+			logger.Infof("  listeners = %v", profile.Listeners)
+		}
+
+		if profile.BlockingGroups != nil {
+			logger.Infof("  blockingGroups = %s", strings.Join(profile.BlockingGroups, ", "))
+		}
+
+		if profile.BlockType != "" {
+			logger.Infof("  blockType = %s", profile.BlockType)
+		}
+
+		if profile.UpstreamGroup != "" {
+			logger.Infof("  upstreamGroup = %s", profile.UpstreamGroup)
+		}
+
+		if profile.FilterQueryTypes != nil {
+			qTypes := make([]string, 0, len(profile.FilterQueryTypes))
+
+			for qType := range profile.FilterQueryTypes {
+				qTypes = append(qTypes, qType.String())
+			}
+
+			slices.Sort(qTypes)
+
+			logger.Infof("  filterQueryTypes = %s", strings.Join(qTypes, ", "))
+		}
+
+		if profile.FQDNOnly != nil {
+			logger.Infof("  fqdnOnly = %t", *profile.FQDNOnly)
+		}
+
+		if profile.SafeSearch != nil {
+			logger.Infof("  safeSearch = %t", *profile.SafeSearch)
+		}
+	}
+}
+
+func (c *Profiles) validate(logger *logrus.Entry, cfg *Config) {
+	for name, profile := range *c {
+		if len(profile.Clients) == 0 && len(profile.Listeners) == 0 {
+			logger.Warnf("profiles.%s: no clients or listeners, the profile is unused", name)
+		}
+
+		for _, listener := range profile.Listeners {
+			if listener.Path != "" && !strings.HasPrefix(listener.Path, "/dns-query/") {
+				logger.Warnf("profiles.%s: DoH listener path '%s' must start with /dns-query/", name, listener.Path)
+			}
+
+			if listener.IP != nil && !listener.isBound(cfg.Ports.DNS) && !listener.isBound(cfg.Ports.TLS) {
+				logger.Warnf("profiles.%s: listener '%s' never matches, no DNS or DoT port is bound to its IP address",
+					name, listener)
+			}
+		}
+
+		for _, group := range profile.BlockingGroups {
+			_, isDenylist := cfg.Blocking.Denylists[group]
+			_, isAllowlist := cfg.Blocking.Allowlists[group]
+
+			if !isDenylist && !isAllowlist {
+				logger.Warnf("profiles.%s: unknown blocking group '%s'", name, group)
+			}
+		}
+
+		if profile.UpstreamGroup != "" && profile.UpstreamGroup != UpstreamDefaultCfgName {
+			if _, ok := cfg.Upstreams.Groups[profile.UpstreamGroup]; !ok {
+				logger.Warnf("profiles.%s: unknown upstream group '%s'", name, profile.UpstreamGroup)
+			}
+		}
+	}
+}
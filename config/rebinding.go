@@ -0,0 +1,32 @@
+package config
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// RebindingProtection configuration for the filtering of answers pointing to private addresses
+type RebindingProtection struct {
+	Enable           bool            `yaml:"enable" default:"false"`
+	Action           RebindingAction `yaml:"action" default:"drop"`
+	AllowedDomains   []string        `yaml:"allowedDomains"`
+	AllowConditional bool            `yaml:"allowConditional" default:"true"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *RebindingProtection) IsEnabled() bool {
+	return c.Enable
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *RebindingProtection) LogConfig(logger *logrus.Entry) {
+	logger.Infof("action = %s", c.Action)
+	logger.Infof("allowConditional = %t", c.AllowConditional)
+
+	if len(c.AllowedDomains) > 0 {
+		logger.Info("allowedDomains:")
+
+		for _, domain := range c.AllowedDomains {
+			logger.Infof("  - %s", domain)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"net"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Recursive configuration for the iterative resolver used by `recursive` upstreams
+type Recursive struct {
+	RootHints           []net.IP `yaml:"rootHints"`
+	QNameMinimisation   bool     `yaml:"qnameMinimisation" default:"true"`
+	MaxReferrals        uint     `yaml:"maxReferrals" default:"30"`
+	MaxDepth            uint     `yaml:"maxDepth" default:"10"`
+	DelegationCacheSize uint     `yaml:"delegationCacheSize" default:"10000"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *Recursive) IsEnabled() bool {
+	// Recursion is enabled by using a `recursive` upstream, the settings always apply
+	return true
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *Recursive) LogConfig(logger *logrus.Entry) {
+	if len(c.RootHints) > 0 {
+		logger.Infof("rootHints = %v", c.RootHints)
+	} else {
+		logger.Info("rootHints = built-in")
+	}
+
+	logger.Infof("qnameMinimisation = %t", c.QNameMinimisation)
+	logger.Debugf("maxReferrals = %d", c.MaxReferrals)
+	logger.Debugf("maxDepth = %d", c.MaxDepth)
+	logger.Debugf("delegationCacheSize = %d", c.DelegationCacheSize)
+}
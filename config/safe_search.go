@@ -0,0 +1,47 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/Abiji-2020/bGuard/log"
+	"github.com/sirupsen/logrus"
+)
+
+// SafeSearch configuration of the enforcement of safe search and restricted modes
+type SafeSearch struct {
+	// client names (with wildcards) or IDs, IPs, CIDR subnets, IP ranges or MAC addresses
+	Clients []string `yaml:"clients"`
+	// engines of the mapping, the first engine wins if engines share a domain
+	Engines []string `yaml:"engines" default:"[\"google\",\"bing\",\"duckduckgo\",\"youtube\"]"`
+	// replace the embedded mapping
+	Sources []BytesSource `yaml:"sources"`
+	TTL     Duration      `yaml:"ttl" default:"1h"`
+	Loading SourceLoading `yaml:"loading"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *SafeSearch) IsEnabled() bool {
+	return len(c.Clients) != 0
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *SafeSearch) LogConfig(logger *logrus.Entry) {
+	logger.Infof("clients = %s", strings.Join(c.Clients, ", "))
+	logger.Infof("engines = %s", strings.Join(c.Engines, ", "))
+	logger.Infof("TTL = %s", c.TTL)
+
+	if len(c.Sources) == 0 {
+		logger.Info("sources = embedded mapping")
+
+		return
+	}
+
+	logger.Info("sources:")
+
+	for _, source := range c.Sources {
+		logger.Infof("  - %s", source)
+	}
+
+	logger.Info("loading:")
+	log.WithIndent(logger, "  ", c.Loading.LogConfig)
+}
@@ -24,12 +24,21 @@ func (u *Upstream) IsDefault() bool {
 	return *u == Upstream{}
 }
 
+// IsRecursive returns true if u resolves iteratively instead of forwarding to a server
+func (u *Upstream) IsRecursive() bool {
+	return u.Net == NetProtocolRecursive
+}
+
 // String returns the string representation of u
 func (u Upstream) String() string {
 	if u.IsDefault() {
 		return "no upstream"
 	}
 
+	if u.IsRecursive() {
+		return u.Net.String()
+	}
+
 	var sb strings.Builder
 
 	sb.WriteString(u.Net.String())
@@ -75,7 +84,12 @@ func (u *Upstream) UnmarshalText(data []byte) error {
 }
 
 // ParseUpstream creates new Upstream from passed string in format [net]:host[:port][/path][#commonname]
+// or the literal `recursive`
 func ParseUpstream(upstream string) (Upstream, error) {
+	if upstream == NetProtocolRecursive.String() {
+		return Upstream{Net: NetProtocolRecursive}, nil
+	}
+
 	var path string
 
 	var port uint16
@@ -9,11 +9,14 @@ const UpstreamDefaultCfgName = "default"
 
 // Upstreams upstream servers configuration
 type Upstreams struct {
-	Init      Init             `yaml:"init"`
-	Timeout   Duration         `yaml:"timeout" default:"2s"` // always > 0
-	Groups    UpstreamGroups   `yaml:"groups"`
-	Strategy  UpstreamStrategy `yaml:"strategy" default:"parallel_best"`
-	UserAgent string           `yaml:"userAgent"`
+	Init              Init             `yaml:"init"`
+	Timeout           Duration         `yaml:"timeout" default:"2s"` // always > 0
+	Groups            UpstreamGroups   `yaml:"groups"`
+	Strategy          UpstreamStrategy `yaml:"strategy" default:"parallel_best"`
+	UserAgent         string           `yaml:"userAgent"`
+	Recursive         Recursive        `yaml:"recursive"`
+	CaseRandomization bool             `yaml:"caseRandomization" default:"false"`
+	Cookies           bool             `yaml:"cookies" default:"false"`
 }
 
 type UpstreamGroups map[string][]Upstream
@@ -39,6 +42,8 @@ func (c *Upstreams) LogConfig(logger *logrus.Entry) {
 
 	logger.Info("timeout: ", c.Timeout)
 	logger.Info("strategy: ", c.Strategy)
+	logger.Info("caseRandomization: ", c.CaseRandomization)
+	logger.Info("cookies: ", c.Cookies)
 	logger.Info("groups:")
 
 	for name, upstreams := range c.Groups {
@@ -48,6 +53,24 @@ func (c *Upstreams) LogConfig(logger *logrus.Entry) {
 			logger.Infof("    - %s", upstream)
 		}
 	}
+
+	if c.usesRecursion() {
+		logger.Info("recursive:")
+		log.WithIndent(logger, "  ", c.Recursive.LogConfig)
+	}
+}
+
+// usesRecursion returns true if any group contains a `recursive` upstream
+func (c *Upstreams) usesRecursion() bool {
+	for _, upstreams := range c.Groups {
+		for _, upstream := range upstreams {
+			if upstream.IsRecursive() {
+				return true
+			}
+		}
+	}
+
+	return false
 }
 
 // UpstreamGroup represents the config for one group (upstream branch)
@@ -0,0 +1,276 @@
+// Package dhcp reads the lease files of DHCP servers
+package dhcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Abiji-2020/bGuard/config"
+	"github.com/Abiji-2020/bGuard/log"
+	"github.com/sirupsen/logrus"
+)
+
+// Lease is an address assigned by a DHCP server
+type Lease struct {
+	IP net.IP
+	// nil if unknown, like for most DHCPv6 leases
+	MAC      net.HardwareAddr
+	Hostname string
+	// zero if the lease doesn't expire
+	Expires time.Time
+}
+
+// HostLabel returns the first label of the host name in lower case, empty if the lease has no host name
+func (l *Lease) HostLabel() string {
+	return hostLabel(l.Hostname)
+}
+
+func hostLabel(hostname string) string {
+	label, _, _ := strings.Cut(strings.ToLower(hostname), ".")
+
+	return label
+}
+
+// IsExpired returns true if the lease expired before now
+func (l *Lease) IsExpired(now time.Time) bool {
+	return !l.Expires.IsZero() && l.Expires.Before(now)
+}
+
+type parseFunc func(r io.Reader) ([]*Lease, error)
+
+var parsers = map[config.LeaseFormat]parseFunc{
+	config.LeaseFormatDnsmasq:  parseDnsmasq,
+	config.LeaseFormatIscDhcpd: parseISCDhcpd,
+	config.LeaseFormatKea:      parseKea,
+	config.LeaseFormatOdhcpd:   parseOdhcpd,
+}
+
+// Parse reads the leases of a lease file.
+// Invalid entries are skipped, and returned as error together with the valid leases.
+func Parse(format config.LeaseFormat, r io.Reader) ([]*Lease, error) {
+	parse, ok := parsers[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported lease format %s", format)
+	}
+
+	return parse(r)
+}
+
+// source is a lease file with the leases of its last version
+type source struct {
+	config.LeaseSource
+
+	modTime time.Time
+	size    int64
+	leases  []*Lease
+}
+
+// Leases holds the leases of lease files, which are reloaded when they change.
+//
+// All methods are safe for concurrent use.
+type Leases struct {
+	sources []*source
+	logger  *logrus.Entry
+
+	lock     sync.RWMutex
+	byIP     map[string]*Lease
+	byName   map[string][]*Lease
+	byMAC    map[string][]*Lease
+	onChange []func()
+}
+
+// NewLeases creates leases from the lease files
+func NewLeases(sources []config.LeaseSource) *Leases {
+	l := &Leases{
+		sources: make([]*source, len(sources)),
+		logger:  log.PrefixedLog("dhcp_leases"),
+	}
+
+	for i, s := range sources {
+		l.sources[i] = &source{LeaseSource: s}
+	}
+
+	return l
+}
+
+// Load reads the lease files, which changed since the last load
+func (l *Leases) Load() error {
+	var errs []error
+
+	changed := false
+
+	for _, s := range l.sources {
+		reloaded, err := s.load()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.Path, err))
+		}
+
+		changed = changed || reloaded
+	}
+
+	if changed {
+		l.index()
+	}
+
+	return errors.Join(errs...)
+}
+
+// load reads the file if it changed, and returns true if it did
+func (s *source) load() (bool, error) {
+	info, err := os.Stat(s.Path)
+	if err != nil {
+		if s.leases != nil && errors.Is(err, os.ErrNotExist) {
+			// the DHCP server may replace the file, keep the last leases
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	if info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return false, nil
+	}
+
+	f, err := os.Open(s.Path)
+	if err != nil {
+		return false, err
+	}
+
+	defer f.Close()
+
+	leases, err := Parse(s.Format, f)
+	if leases == nil && err != nil {
+		return false, err
+	}
+
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+	s.leases = leases
+
+	return true, err
+}
+
+// index rebuilds the lookup maps, later sources override earlier ones
+func (l *Leases) index() {
+	byIP := make(map[string]*Lease)
+
+	for _, s := range l.sources {
+		for _, lease := range s.leases {
+			byIP[lease.IP.String()] = lease
+		}
+	}
+
+	byName := make(map[string][]*Lease)
+	byMAC := make(map[string][]*Lease)
+
+	for _, lease := range byIP {
+		if lease.Hostname != "" {
+			name := hostLabel(lease.Hostname)
+			byName[name] = append(byName[name], lease)
+		}
+
+		if lease.MAC != nil {
+			mac := lease.MAC.String()
+			byMAC[mac] = append(byMAC[mac], lease)
+		}
+	}
+
+	l.lock.Lock()
+	l.byIP = byIP
+	l.byName = byName
+	l.byMAC = byMAC
+	onChange := l.onChange
+	l.lock.Unlock()
+
+	for _, fn := range onChange {
+		fn()
+	}
+}
+
+// OnChange registers a function, which is called after changed lease files were loaded
+func (l *Leases) OnChange(fn func()) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.onChange = append(l.onChange, fn)
+}
+
+// Watch reloads the changed lease files every interval until ctx is done, it returns at once if interval is not positive
+func (l *Leases) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := l.Load(); err != nil {
+				l.logger.Warn("can't load DHCP leases: ", err)
+			}
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// ByIP returns the active lease of the IP or nil
+func (l *Leases) ByIP(ip net.IP) *Lease {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	lease, ok := l.byIP[ip.String()]
+	if !ok || lease.IsExpired(time.Now()) {
+		return nil
+	}
+
+	return lease
+}
+
+// ByHostname returns the active leases of the host name, which is case-insensitive.
+// Leases with a fully qualified host name are found by its first label.
+func (l *Leases) ByHostname(name string) []*Lease {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	return active(l.byName[strings.ToLower(name)])
+}
+
+// ByMAC returns the active leases of the hardware address
+func (l *Leases) ByMAC(mac net.HardwareAddr) []*Lease {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	return active(l.byMAC[mac.String()])
+}
+
+// Len returns the number of leases, including expired ones
+func (l *Leases) Len() int {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	return len(l.byIP)
+}
+
+func active(leases []*Lease) []*Lease {
+	now := time.Now()
+	result := make([]*Lease, 0, len(leases))
+
+	for _, lease := range leases {
+		if !lease.IsExpired(now) {
+			result = append(result, lease)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,403 @@
+package dhcp
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLineErrors limits the errors returned for invalid entries of a file
+const maxLineErrors = 5
+
+// lineErrors collects the errors of invalid entries
+type lineErrors struct {
+	errs  []error
+	count int
+}
+
+func (e *lineErrors) add(line int, err error) {
+	e.count++
+
+	if len(e.errs) < maxLineErrors {
+		e.errs = append(e.errs, fmt.Errorf("line %d: %w", line, err))
+	}
+}
+
+func (e *lineErrors) err() error {
+	if e.count > len(e.errs) {
+		e.errs = append(e.errs, fmt.Errorf("%d more invalid entries", e.count-len(e.errs)))
+	}
+
+	return errors.Join(e.errs...)
+}
+
+// leaseMap keeps the last lease of each IP in the order of appearance
+type leaseMap struct {
+	order  []string
+	leases map[string]*Lease
+}
+
+func newLeaseMap() *leaseMap {
+	return &leaseMap{leases: make(map[string]*Lease)}
+}
+
+func (m *leaseMap) put(lease *Lease) {
+	key := lease.IP.String()
+
+	if _, ok := m.leases[key]; !ok {
+		m.order = append(m.order, key)
+	}
+
+	m.leases[key] = lease
+}
+
+func (m *leaseMap) remove(ip net.IP) {
+	delete(m.leases, ip.String())
+}
+
+func (m *leaseMap) list() []*Lease {
+	result := make([]*Lease, 0, len(m.leases))
+
+	for _, key := range m.order {
+		if lease, ok := m.leases[key]; ok {
+			result = append(result, lease)
+		}
+	}
+
+	return result
+}
+
+func parseIP(s string) (net.IP, error) {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP '%s'", s)
+	}
+
+	return ip, nil
+}
+
+// unixTime converts an expiry timestamp, where 0 means no expiry
+func unixTime(s string) (time.Time, error) {
+	sec, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp '%s'", s)
+	}
+
+	if sec <= 0 {
+		return time.Time{}, nil
+	}
+
+	return time.Unix(sec, 0), nil
+}
+
+// parseDnsmasq reads dnsmasq.leases:
+//
+//	<expiry> <MAC or IAID for IPv6> <IP> <hostname or *> <client ID or *>
+func parseDnsmasq(r io.Reader) ([]*Lease, error) {
+	leases := newLeaseMap()
+
+	var errs lineErrors
+
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] == "duid" {
+			continue
+		}
+
+		if len(fields) < 4 { //nolint:mnd
+			errs.add(line, errors.New("too few fields"))
+
+			continue
+		}
+
+		expires, err := unixTime(fields[0])
+		if err != nil {
+			errs.add(line, err)
+
+			continue
+		}
+
+		ip, err := parseIP(fields[2])
+		if err != nil {
+			errs.add(line, err)
+
+			continue
+		}
+
+		// IPv6 leases have the IAID instead of the MAC
+		mac, _ := net.ParseMAC(fields[1])
+
+		hostname := fields[3]
+		if hostname == "*" {
+			hostname = ""
+		}
+
+		leases.put(&Lease{IP: ip, MAC: mac, Hostname: hostname, Expires: expires})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return leases.list(), errs.err()
+}
+
+// parseISCDhcpd reads the lease declarations of dhcpd.leases, later declarations of an IP replace earlier ones:
+//
+//	lease 192.168.1.10 {
+//	  ends 4 2024/01/04 22:00:00;
+//	  binding state active;
+//	  hardware ethernet 00:11:22:33:44:55;
+//	  client-hostname "laptop";
+//	}
+func parseISCDhcpd(r io.Reader) ([]*Lease, error) {
+	leases := newLeaseMap()
+
+	var (
+		errs   lineErrors
+		lease  *Lease
+		active bool
+	)
+
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+
+		if lease == nil {
+			fields := strings.Fields(text)
+
+			if len(fields) == 3 && fields[0] == "lease" && fields[2] == "{" { //nolint:mnd
+				ip, err := parseIP(fields[1])
+				if err != nil {
+					errs.add(line, err)
+
+					continue
+				}
+
+				lease = &Lease{IP: ip}
+				active = true
+			}
+
+			continue
+		}
+
+		if text == "}" {
+			if active {
+				leases.put(lease)
+			} else {
+				leases.remove(lease.IP)
+			}
+
+			lease = nil
+
+			continue
+		}
+
+		key, value, _ := strings.Cut(strings.TrimSuffix(text, ";"), " ")
+
+		switch key {
+		case "ends":
+			expires, err := parseISCTime(value)
+			if err != nil {
+				errs.add(line, err)
+			}
+
+			lease.Expires = expires
+
+		case "binding":
+			active = value == "state active"
+
+		case "hardware":
+			if _, mac, ok := strings.Cut(value, " "); ok {
+				lease.MAC, _ = net.ParseMAC(mac)
+			}
+
+		case "client-hostname":
+			lease.Hostname = strings.Trim(value, `"`)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return leases.list(), errs.err()
+}
+
+// parseISCTime parses the times of dhcpd.leases, which are "never", "epoch <seconds>" or "<weekday> <date> <time>" in UTC
+func parseISCTime(value string) (time.Time, error) {
+	fields := strings.Fields(value)
+
+	switch {
+	case len(fields) == 1 && fields[0] == "never":
+		return time.Time{}, nil
+
+	case len(fields) >= 2 && fields[0] == "epoch":
+		return unixTime(fields[1])
+
+	case len(fields) >= 3: //nolint:mnd
+		return time.Parse("2006/01/02 15:04:05", fields[1]+" "+fields[2])
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time '%s'", value)
+}
+
+// parseKea reads Kea's memfile CSV for DHCPv4 or DHCPv6. Kea appends changed leases, so later rows replace earlier ones.
+func parseKea(r io.Reader) ([]*Lease, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("can't read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+
+	for _, name := range []string{"address", "expire", "valid_lifetime"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("CSV header has no column '%s'", name)
+		}
+	}
+
+	leases := newLeaseMap()
+
+	var errs lineErrors
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			errs.add(line, err)
+
+			continue
+		}
+
+		column := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return record[i]
+			}
+
+			return ""
+		}
+
+		ip, err := parseIP(column("address"))
+		if err != nil {
+			errs.add(line, err)
+
+			continue
+		}
+
+		// state 0 is assigned, a lifetime of 0 means the lease was released
+		if state := column("state"); (state != "" && state != "0") || column("valid_lifetime") == "0" {
+			leases.remove(ip)
+
+			continue
+		}
+
+		expires, err := unixTime(column("expire"))
+		if err != nil {
+			errs.add(line, err)
+
+			continue
+		}
+
+		mac, _ := net.ParseMAC(column("hwaddr"))
+
+		leases.put(&Lease{
+			IP:       ip,
+			MAC:      mac,
+			Hostname: strings.TrimSuffix(column("hostname"), "."),
+			Expires:  expires,
+		})
+	}
+
+	return leases.list(), errs.err()
+}
+
+// parseOdhcpd reads the lease file of OpenWrt's odhcpd, where each assignment is a comment line:
+//
+//	# <interface> <DUID or MAC> <IAID or "ipv4"> <hostname> <valid until> <assignment> <prefix length> <address/length>...
+func parseOdhcpd(r io.Reader) ([]*Lease, error) {
+	const minFields = 9
+
+	leases := newLeaseMap()
+
+	var errs lineErrors
+
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] != "#" {
+			// the file also contains the host names in hosts file format, which are redundant
+			continue
+		}
+
+		if len(fields) < minFields {
+			errs.add(line, errors.New("too few fields"))
+
+			continue
+		}
+
+		expires, err := unixTime(fields[5])
+		if err != nil {
+			errs.add(line, err)
+
+			continue
+		}
+
+		if fields[5] == "0" {
+			// expired
+			continue
+		}
+
+		var mac net.HardwareAddr
+
+		if fields[3] == "ipv4" {
+			mac, _ = hex.DecodeString(fields[2])
+		}
+
+		hostname := fields[4]
+		if hostname == "-" {
+			hostname = ""
+		}
+
+		for _, address := range fields[8:] {
+			ipStr, _, _ := strings.Cut(address, "/")
+
+			ip, err := parseIP(ipStr)
+			if err != nil {
+				errs.add(line, err)
+
+				continue
+			}
+
+			leases.put(&Lease{IP: ip, MAC: mac, Hostname: hostname, Expires: expires})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return leases.list(), errs.err()
+}
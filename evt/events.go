@@ -11,6 +11,14 @@ const (
 	// BlockingCacheGroupChanged fires, if a list group is changed. Parameter: list type, group name, element count
 	BlockingCacheGroupChanged = "blocking:cachingGroupChanged"
 
+	// BlockingScheduleChanged fires, if a blocking schedule becomes active or inactive.
+	// Parameter: schedule name, boolean (active = true)
+	BlockingScheduleChanged = "blocking:scheduleChanged"
+
+	// BlockingGeoIPMatched fires, if an answer IP is located in a blocked or flagged country.
+	// Parameter: country code, action ("block" or "flag")
+	BlockingGeoIPMatched = "blocking:geoIPMatched"
+
 	// CachingDomainPrefetched fires if a domain will be prefetched, Parameter: domain name
 	CachingDomainPrefetched = "caching:prefetched"
 
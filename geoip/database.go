@@ -0,0 +1,94 @@
+// Package geoip looks up the autonomous system and the country of IP addresses in a local MMDB database file.
+package geoip
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Database is a MaxMind DB (MMDB) file, e.g. GeoLite2-ASN or a compatible database
+type Database struct {
+	reader *maxminddb.Reader
+	path   string
+}
+
+// Open opens the database file
+func Open(path string) (*Database, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open database '%s': %w", path, err)
+	}
+
+	return &Database{reader: reader, path: path}, nil
+}
+
+// String implements `fmt.Stringer`.
+func (d *Database) String() string {
+	return fmt.Sprintf("%s (%s)", d.path, d.reader.Metadata.DatabaseType)
+}
+
+// ASN returns the number of the autonomous system the IP belongs to
+func (d *Database) ASN(ip net.IP) (uint, bool) {
+	var record struct {
+		// MaxMind format
+		Number uint `maxminddb:"autonomous_system_number"`
+		// IPinfo format, e.g. "AS15169"
+		ASN string `maxminddb:"asn"`
+	}
+
+	if err := d.reader.Lookup(ip, &record); err != nil {
+		return 0, false
+	}
+
+	if record.Number != 0 {
+		return record.Number, true
+	}
+
+	return ParseASN(record.ASN)
+}
+
+// Country returns the ISO 3166-1 code of the country the IP is located in, e.g. "DE"
+func (d *Database) Country(ip net.IP) (string, bool) {
+	var record struct {
+		// MaxMind format: object with ISO code, IPinfo format: ISO code
+		Country any `maxminddb:"country"`
+	}
+
+	if err := d.reader.Lookup(ip, &record); err != nil {
+		return "", false
+	}
+
+	var code string
+
+	switch country := record.Country.(type) {
+	case map[string]any:
+		code, _ = country["iso_code"].(string)
+	case string:
+		code = country
+	}
+
+	return strings.ToUpper(code), code != ""
+}
+
+// ParseASN parses an autonomous system number with "AS" prefix, e.g. "AS15169"
+func ParseASN(s string) (uint, bool) {
+	const (
+		base    = 10
+		bitSize = 32
+	)
+
+	if len(s) < len("AS0") || !strings.EqualFold(s[:2], "AS") {
+		return 0, false
+	}
+
+	number, err := strconv.ParseUint(s[2:], base, bitSize)
+	if err != nil {
+		return 0, false
+	}
+
+	return uint(number), true
+}
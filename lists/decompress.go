@@ -0,0 +1,208 @@
+package lists
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/Abiji-2020/bGuard/config"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// magic bytes of the compression formats
+var compressionMagic = map[config.BytesSourceCompression][]byte{ //nolint:gochecknoglobals
+	config.BytesSourceCompressionGzip: {0x1f, 0x8b},
+	config.BytesSourceCompressionZstd: {0x28, 0xb5, 0x2f, 0xfd},
+	config.BytesSourceCompressionXz:   {0xfd, '7', 'z', 'X', 'Z', 0x00},
+	config.BytesSourceCompressionZip:  {'P', 'K', 0x03, 0x04},
+}
+
+// content types of the compression formats
+var compressionContentTypes = map[string]config.BytesSourceCompression{ //nolint:gochecknoglobals
+	"application/gzip":   config.BytesSourceCompressionGzip,
+	"application/x-gzip": config.BytesSourceCompressionGzip,
+	"application/zstd":   config.BytesSourceCompressionZstd,
+	"application/x-xz":   config.BytesSourceCompressionXz,
+	"application/zip":    config.BytesSourceCompressionZip,
+}
+
+// maxMagicLen is the length of the longest magic bytes
+const maxMagicLen = 6
+
+// readCloser closes the decompressing reader and the underlying file
+type readCloser struct {
+	io.Reader
+	closers []func() error
+}
+
+func (r *readCloser) Close() error {
+	var errs []error
+
+	for _, closeFn := range r.closers {
+		errs = append(errs, closeFn())
+	}
+
+	return errors.Join(errs...)
+}
+
+// decompress returns a reader of the decompressed content of the source, with automatic compression it is
+// detected from the content type of a download or the magic bytes
+func decompress(source config.BytesSource, rc io.ReadCloser) (io.ReadCloser, error) {
+	compression := source.Compression
+	br := bufio.NewReader(rc)
+
+	if compression == config.BytesSourceCompressionAuto {
+		compression = detectCompression(rc, br)
+	}
+
+	var (
+		r   io.Reader
+		err error
+	)
+
+	closers := []func() error{rc.Close}
+
+	switch compression {
+	case config.BytesSourceCompressionGzip:
+		var gr *gzip.Reader
+
+		gr, err = gzip.NewReader(br)
+		if err == nil {
+			r = gr
+			closers = append(closers, gr.Close)
+		}
+	case config.BytesSourceCompressionZstd:
+		var zr *zstd.Decoder
+
+		zr, err = zstd.NewReader(br)
+		if err == nil {
+			r = zr
+			closers = append(closers, func() error { zr.Close(); return nil })
+		}
+	case config.BytesSourceCompressionXz:
+		r, err = xz.NewReader(br)
+	case config.BytesSourceCompressionZip:
+		var memberClosers []func() error
+
+		r, memberClosers, err = zipMembers(br, source.Members)
+		closers = append(closers, memberClosers...)
+	default:
+		r = br
+	}
+
+	if err != nil {
+		_ = rc.Close()
+
+		return nil, fmt.Errorf("can't decompress %s (%s): %w", source, compression, err)
+	}
+
+	return &readCloser{Reader: r, closers: closers}, nil
+}
+
+// detectCompression returns the compression of the magic bytes, or the one of the content type.
+// The magic bytes come first: the HTTP client already decodes a `Content-Encoding: gzip` body,
+// while the content type of the file may still say it is compressed.
+func detectCompression(rc io.ReadCloser, br *bufio.Reader) config.BytesSourceCompression {
+	// a short file can't be compressed, the error is returned when reading
+	head, _ := br.Peek(maxMagicLen)
+
+	for compression, magic := range compressionMagic {
+		if bytes.HasPrefix(head, magic) {
+			return compression
+		}
+	}
+
+	if file, ok := rc.(interface{ ContentType() string }); ok {
+		if mediaType, _, err := mime.ParseMediaType(file.ContentType()); err == nil {
+			if compression, ok := compressionContentTypes[mediaType]; ok {
+				return compression
+			}
+		}
+	}
+
+	return config.BytesSourceCompressionNone
+}
+
+// zipMembers returns the content of the archive members matching one of the patterns, or of all files,
+// and the functions to close the members and remove the spooled archive.
+// Each member ends with a line break, so the last line of a member and the first line of the next one aren't joined.
+func zipMembers(r io.Reader, patterns []string) (io.Reader, []func() error, error) {
+	// the central directory is at the end of the archive, so it is spooled to a temporary file instead of memory
+	spool, err := os.CreateTemp("", "bGuard-*.zip")
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't create temporary file: %w", err)
+	}
+
+	closers := []func() error{spool.Close, func() error { return os.Remove(spool.Name()) }}
+
+	closeAll := func() {
+		for _, closeFn := range closers {
+			_ = closeFn()
+		}
+	}
+
+	size, err := io.Copy(spool, r)
+	if err != nil {
+		closeAll()
+
+		return nil, nil, err
+	}
+
+	archive, err := zip.NewReader(spool, size)
+	if err != nil {
+		closeAll()
+
+		return nil, nil, err
+	}
+
+	var readers []io.Reader
+
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() || !matchesMember(file.Name, patterns) {
+			continue
+		}
+
+		content, err := file.Open()
+		if err != nil {
+			closeAll()
+
+			return nil, nil, fmt.Errorf("can't open member '%s': %w", file.Name, err)
+		}
+
+		readers = append(readers, content, strings.NewReader("\n"))
+		// the members are closed before the spooled archive
+		closers = append([]func() error{content.Close}, closers...)
+	}
+
+	if len(readers) == 0 {
+		closeAll()
+
+		return nil, nil, fmt.Errorf("no archive member matches %v", patterns)
+	}
+
+	return io.MultiReader(readers...), closers, nil
+}
+
+func matchesMember(name string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
+}
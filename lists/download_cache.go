@@ -0,0 +1,157 @@
+package lists
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+const (
+	downloadCacheDirPerm  = 0o750
+	downloadCacheFilePerm = 0o640
+)
+
+// downloadCache keeps the downloaded files with their ETag and Last-Modified in a directory,
+// to send conditional requests and to use them if a download fails
+type downloadCache struct {
+	dir string
+}
+
+// cachedFileInfo is stored next to a cached file
+type cachedFileInfo struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	ContentType  string `json:"contentType,omitempty"`
+}
+
+func newDownloadCache(dir string) *downloadCache {
+	if dir == "" {
+		return nil
+	}
+
+	return &downloadCache{dir: dir}
+}
+
+// paths returns the paths of the cached file and of its info
+func (c *downloadCache) paths(link string) (data, info string) {
+	hash := sha256.Sum256([]byte(link))
+	name := hex.EncodeToString(hash[:])
+
+	return filepath.Join(c.dir, name+".list"), filepath.Join(c.dir, name+".json")
+}
+
+func (c *downloadCache) readInfo(link string) (*cachedFileInfo, error) {
+	dataPath, infoPath := c.paths(link)
+
+	if _, err := os.Stat(dataPath); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(infoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var info cachedFileInfo
+
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("invalid cache info %s: %w", infoPath, err)
+	}
+
+	return &info, nil
+}
+
+// setConditionalHeaders sets `If-None-Match` and `If-Modified-Since` of the cached file, if there is one
+func (c *downloadCache) setConditionalHeaders(req *http.Request, link string) {
+	info, err := c.readInfo(link)
+	if err != nil {
+		return
+	}
+
+	if info.ETag != "" {
+		req.Header.Set("If-None-Match", info.ETag)
+	}
+
+	if info.LastModified != "" {
+		req.Header.Set("If-Modified-Since", info.LastModified)
+	}
+}
+
+// store writes the body of the response to the cache and returns the cached file
+func (c *downloadCache) store(link string, resp *http.Response) (*downloadedFile, error) {
+	defer resp.Body.Close()
+
+	if err := os.MkdirAll(c.dir, downloadCacheDirPerm); err != nil {
+		return nil, fmt.Errorf("can't create cache directory: %w", err)
+	}
+
+	dataPath, infoPath := c.paths(link)
+
+	tmp, err := os.CreateTemp(c.dir, filepath.Base(dataPath)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("can't create cache file: %w", err)
+	}
+
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, resp.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("can't write cache file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), dataPath); err != nil {
+		return nil, fmt.Errorf("can't write cache file: %w", err)
+	}
+
+	info, err := json.Marshal(cachedFileInfo{
+		URL:          link,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		ContentType:  contentType(resp),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(infoPath, info, downloadCacheFilePerm); err != nil {
+		return nil, fmt.Errorf("can't write cache info: %w", err)
+	}
+
+	return c.open(link)
+}
+
+// open returns the cached file of the link
+func (c *downloadCache) open(link string) (*downloadedFile, error) {
+	info, err := c.readInfo(link)
+	if err != nil {
+		return nil, err
+	}
+
+	dataPath, _ := c.paths(link)
+
+	file, err := os.Open(dataPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &downloadedFile{ReadCloser: file, contentType: info.ContentType, version: info.version()}, nil
+}
+
+// version identifies the content of the cached file, it is empty without validators
+func (i *cachedFileInfo) version() string {
+	if i.ETag == "" && i.LastModified == "" {
+		return ""
+	}
+
+	return i.ETag + "|" + i.LastModified
+}
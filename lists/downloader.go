@@ -31,11 +31,39 @@ type FileDownloader interface {
 	DownloadFile(ctx context.Context, link string) (io.ReadCloser, error)
 }
 
+// downloadedFile is the body of a download or the cached file, its content type is used to detect the compression
+type downloadedFile struct {
+	io.ReadCloser
+	contentType string
+	// ETag and Last-Modified of the cached file, empty without cache or validators
+	version string
+}
+
+func (f *downloadedFile) ContentType() string {
+	return f.contentType
+}
+
+// contentType returns the content type of the response, unless the transport already decoded a gzip body
+func contentType(resp *http.Response) string {
+	if resp.Uncompressed {
+		return ""
+	}
+
+	return resp.Header.Get("Content-Type")
+}
+
+// Version identifies the content of the cached file by its ETag and Last-Modified, it is empty if the server sent neither
+func (f *downloadedFile) Version() string {
+	return f.version
+}
+
 // httpDownloader downloads files via HTTP protocol
 type httpDownloader struct {
 	cfg config.Downloader
 
 	client http.Client
+	// nil without cache directory
+	cache *downloadCache
 }
 
 func NewDownloader(cfg config.Downloader, transport http.RoundTripper) FileDownloader {
@@ -50,6 +78,7 @@ func newDownloader(cfg config.Downloader, transport http.RoundTripper) *httpDown
 			Transport: transport,
 			Timeout:   cfg.Timeout.ToDuration(),
 		},
+		cache: newDownloadCache(cfg.CacheDir),
 	}
 }
 
@@ -63,12 +92,27 @@ func (d *httpDownloader) DownloadFile(ctx context.Context, link string) (io.Read
 				return err
 			}
 
+			if d.cache != nil {
+				d.cache.setConditionalHeaders(req, link)
+			}
+
 			resp, httpErr := d.client.Do(req)
 			if httpErr == nil {
-				if resp.StatusCode == http.StatusOK {
-					body = resp.Body
+				switch {
+				case resp.StatusCode == http.StatusOK && d.cache == nil:
+					body = &downloadedFile{ReadCloser: resp.Body, contentType: contentType(resp)}
 
 					return nil
+				case resp.StatusCode == http.StatusOK:
+					body, err = d.cache.store(link, resp)
+
+					return err
+				case resp.StatusCode == http.StatusNotModified && d.cache != nil:
+					_ = resp.Body.Close()
+
+					body, err = d.cache.open(link)
+
+					return err
 				}
 
 				_ = resp.Body.Close()
@@ -108,6 +152,14 @@ func (d *httpDownloader) DownloadFile(ctx context.Context, link string) (io.Read
 			onDownloadError(link)
 		}))
 
+	if err != nil && d.cache != nil {
+		if cached, cacheErr := d.cache.open(link); cacheErr == nil {
+			logger().WithField("link", link).Warnf("Can't download file, using cached copy: %s", err)
+
+			return cached, nil
+		}
+	}
+
 	return body, err
 }
 
@@ -0,0 +1,266 @@
+package lists
+
+import (
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+
+	"github.com/Abiji-2020/bGuard/lists/parsers"
+
+	"github.com/miekg/dns"
+)
+
+// FilterQuery is the query matched against adblock rules with `$client` or `$dnstype` modifier
+type FilterQuery struct {
+	ClientIP    net.IP
+	ClientNames []string
+	QType       uint16
+}
+
+// filterRules keeps the adblock rules of the groups, which can't be matched by domain only
+type filterRules struct {
+	lock   sync.RWMutex
+	groups map[string]*filterRuleGroup
+}
+
+type filterRuleGroup struct {
+	// rules by domain
+	rules map[string][]*filterRule
+	count int
+}
+
+type filterRule struct {
+	parsers.AdblockRule
+	// text of the rule without `$badfilter`
+	key     string
+	clients []filterValue[netip.Prefix]
+	qTypes  []filterValue[uint16]
+}
+
+// filterValue is a value of a modifier, a negated value excludes matching queries
+type filterValue[T any] struct {
+	value   T
+	name    string
+	negated bool
+}
+
+// filterMatch is the result of matching a domain against the rules of the groups
+type filterMatch struct {
+	// groups of the matching blocking rules
+	blocked []string
+	// groups of the matching blocking rules with `$important`
+	importantBlocked []string
+	excepted         bool
+	// an important exception also takes precedence over important blocking rules
+	importantExcepted bool
+}
+
+func newFilterRules() *filterRules {
+	return &filterRules{groups: make(map[string]*filterRuleGroup)}
+}
+
+func newFilterRule(rule parsers.AdblockRule) *filterRule {
+	res := &filterRule{AdblockRule: rule}
+
+	key := rule
+	key.BadFilter = false
+	res.key = key.String()
+
+	for _, client := range rule.Clients {
+		name, negated := strings.CutPrefix(client, "~")
+		value := filterValue[netip.Prefix]{name: strings.ToLower(name), negated: negated}
+
+		if prefix, err := netip.ParsePrefix(name); err == nil {
+			value.value = prefix.Masked()
+		} else if addr, err := netip.ParseAddr(name); err == nil {
+			value.value = netip.PrefixFrom(addr, addr.BitLen())
+		}
+
+		res.clients = append(res.clients, value)
+	}
+
+	for _, qType := range rule.DNSTypes {
+		name, negated := strings.CutPrefix(qType, "~")
+
+		res.qTypes = append(res.qTypes, filterValue[uint16]{value: dns.StringToType[name], name: name, negated: negated})
+	}
+
+	return res
+}
+
+func (c *filterRules) elementCount(group string) int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if g, ok := c.groups[group]; ok {
+		return g.count
+	}
+
+	return 0
+}
+
+// refresh returns a factory to replace the rules of the group
+func (c *filterRules) refresh(group string) *filterRuleFactory {
+	return &filterRuleFactory{
+		rules: &filterRuleGroup{rules: make(map[string][]*filterRule)},
+		finishFn: func(g *filterRuleGroup) {
+			c.lock.Lock()
+			defer c.lock.Unlock()
+
+			if g.count != 0 {
+				c.groups[group] = g
+			} else {
+				delete(c.groups, group)
+			}
+		},
+	}
+}
+
+type filterRuleFactory struct {
+	rules    *filterRuleGroup
+	finishFn func(*filterRuleGroup)
+}
+
+// addEntry adds the entry, if it is an adblock rule
+func (f *filterRuleFactory) addEntry(entry string) bool {
+	if !strings.HasPrefix(entry, "|") && !strings.HasPrefix(entry, "@@") {
+		return false
+	}
+
+	var rule parsers.AdblockRule
+
+	if err := rule.UnmarshalText([]byte(entry)); err != nil {
+		return false
+	}
+
+	f.rules.rules[rule.Domain] = append(f.rules.rules[rule.Domain], newFilterRule(rule))
+	f.rules.count++
+
+	return true
+}
+
+func (f *filterRuleFactory) finish() {
+	f.finishFn(f.rules)
+}
+
+// match matches the domain and its parent domains against the rules of the groups.
+// Rules with `$client` or `$dnstype` modifier don't match without query.
+func (c *filterRules) match(domain string, query *FilterQuery, groupsToCheck []string) filterMatch {
+	type candidate struct {
+		group string
+		rule  *filterRule
+	}
+
+	var (
+		candidates []candidate
+		badFilters = make(map[string]struct{})
+	)
+
+	c.lock.RLock()
+
+	for _, group := range groupsToCheck {
+		g, ok := c.groups[group]
+		if !ok {
+			continue
+		}
+
+		for name, exact := domain, true; ; exact = false {
+			for _, rule := range g.rules[name] {
+				switch {
+				case rule.Exact && !exact:
+				case rule.BadFilter:
+					badFilters[rule.key] = struct{}{}
+				default:
+					candidates = append(candidates, candidate{group, rule})
+				}
+			}
+
+			i := strings.IndexByte(name, '.')
+			if i < 0 {
+				break
+			}
+
+			name = name[i+1:]
+		}
+	}
+
+	c.lock.RUnlock()
+
+	var res filterMatch
+
+	for _, candidate := range candidates {
+		rule := candidate.rule
+
+		if _, disabled := badFilters[rule.key]; disabled || !rule.appliesTo(query) {
+			continue
+		}
+
+		switch {
+		case rule.Exception && rule.Important:
+			res.importantExcepted = true
+		case rule.Exception:
+			res.excepted = true
+		case rule.Important:
+			res.importantBlocked = append(res.importantBlocked, candidate.group)
+		default:
+			res.blocked = append(res.blocked, candidate.group)
+		}
+	}
+
+	return res
+}
+
+// appliesTo returns true if the `$client` and `$dnstype` modifiers of the rule match the query
+func (r *filterRule) appliesTo(query *FilterQuery) bool {
+	if len(r.clients) == 0 && len(r.qTypes) == 0 {
+		return true
+	}
+
+	if query == nil {
+		return false
+	}
+
+	return matchesValues(r.clients, query.matchesClient) && matchesValues(r.qTypes, func(v filterValue[uint16]) bool {
+		return v.value == query.QType
+	})
+}
+
+func (q *FilterQuery) matchesClient(v filterValue[netip.Prefix]) bool {
+	if v.value.IsValid() {
+		addr, ok := netip.AddrFromSlice(q.ClientIP)
+
+		return ok && v.value.Contains(addr.Unmap())
+	}
+
+	for _, name := range q.ClientNames {
+		if strings.EqualFold(name, v.name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesValues returns true if no negated value matches, and one of the other values matches if there are any
+func matchesValues[T any](values []filterValue[T], matches func(filterValue[T]) bool) bool {
+	positive, matched := false, false
+
+	for _, v := range values {
+		if v.negated {
+			if matches(v) {
+				return false
+			}
+
+			continue
+		}
+
+		positive = true
+
+		if !matched && matches(v) {
+			matched = true
+		}
+	}
+
+	return !positive || matched
+}
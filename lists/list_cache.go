@@ -5,7 +5,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
+	"net/netip"
+	"slices"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 
@@ -31,19 +35,27 @@ type ListCacheType int
 
 // Matcher checks if a domain is in a list
 type Matcher interface {
-	// Match matches passed domain name against cached list entries
-	Match(domain string, groupsToCheck []string) (groups []string)
+	// Match matches passed domain name against cached list entries,
+	// adblock rules with `$client` or `$dnstype` modifier only match with query
+	Match(domain string, groupsToCheck []string, query *FilterQuery) (groups []string)
 }
 
 // ListCache generic cache of strings divided in groups
 type ListCache struct {
 	groupedCache stringcache.GroupedStringCache
 	regexCache   stringcache.GroupedStringCache
+	filterRules  *filterRules
+	rpzRules     *rpzRules
 
 	cfg          config.SourceLoading
 	listType     ListCacheType
 	groupSources map[string][]config.BytesSource
 	downloader   FileDownloader
+
+	// versions of the downloaded files each group was last parsed from, by source index.
+	// The download cache is shared, so a file another group already downloaded can be new to this group.
+	parsedLock     sync.Mutex
+	parsedVersions map[string][]string
 }
 
 // LogConfig implements `config.Configurable`.
@@ -52,7 +64,7 @@ func (b *ListCache) LogConfig(logger *logrus.Entry) {
 	regexes := 0
 
 	for group := range b.groupSources {
-		count := b.groupedCache.ElementCount(group)
+		count := b.elementCount(group)
 		logger.Infof("%s: %d entries", group, count)
 		total += count
 		regexes += b.regexCache.ElementCount(group)
@@ -72,6 +84,7 @@ func (b *ListCache) LogConfig(logger *logrus.Entry) {
 func NewListCache(ctx context.Context,
 	t ListCacheType, cfg config.SourceLoading,
 	groupSources map[string][]config.BytesSource, downloader FileDownloader,
+	asnLookup stringcache.ASNLookup,
 ) (*ListCache, error) {
 	regexCache := stringcache.NewInMemoryGroupedRegexCache()
 
@@ -79,14 +92,20 @@ func NewListCache(ctx context.Context,
 		groupedCache: stringcache.NewChainedGroupedCache(
 			regexCache,
 			stringcache.NewInMemoryGroupedWildcardCache(), // must be after regex which can contain '*'
-			stringcache.NewInMemoryGroupedStringCache(),   // accepts all values, must be last
+			stringcache.NewInMemoryGroupedIPCache(),       // must be after regex which can contain '/'
+			stringcache.NewInMemoryGroupedASNCache(asnLookup),
+			stringcache.NewInMemoryGroupedStringCache(), // accepts all values, must be last
 		),
-		regexCache: regexCache,
+		regexCache:  regexCache,
+		filterRules: newFilterRules(),
+		rpzRules:    newRPZRules(),
 
 		cfg:          cfg,
 		listType:     t,
 		groupSources: groupSources,
 		downloader:   downloader,
+
+		parsedVersions: make(map[string][]string),
 	}
 
 	err := cfg.StartPeriodicRefresh(ctx, c.refresh, func(err error) {
@@ -103,9 +122,46 @@ func logger() *logrus.Entry {
 	return log.PrefixedLog("list_cache")
 }
 
-// Match matches passed domain name against cached list entries
-func (b *ListCache) Match(domain string, groupsToCheck []string) (groups []string) {
-	return b.groupedCache.Contains(domain, groupsToCheck)
+// Match matches passed domain name against cached list entries,
+// adblock rules with `$client` or `$dnstype` modifier only match with query
+func (b *ListCache) Match(domain string, groupsToCheck []string, query *FilterQuery) (groups []string) {
+	groups = b.groupedCache.Contains(domain, groupsToCheck)
+
+	rules := b.filterRules.match(domain, query, groupsToCheck)
+
+	switch {
+	case rules.importantExcepted:
+		return nil
+	case rules.excepted:
+		// exceptions of any group apply to the entries of all groups
+		groups = rules.importantBlocked
+	default:
+		groups = append(append(groups, rules.blocked...), rules.importantBlocked...)
+	}
+
+	slices.Sort(groups)
+
+	return slices.Compact(groups)
+}
+
+// MatchRPZName returns the rule of the response policy zones matching the domain, e.g. the query name for
+// QNAME triggers or the name of a name server for NSDNAME triggers
+func (b *ListCache) MatchRPZName(trigger parsers.RPZTrigger, domain string, groupsToCheck []string) (*RPZMatch, bool) {
+	return b.rpzRules.matchName(trigger, domain, groupsToCheck)
+}
+
+// MatchRPZIP returns the RPZ-IP rule of the response policy zones matching the answer IP
+func (b *ListCache) MatchRPZIP(ip net.IP, groupsToCheck []string) (*RPZMatch, bool) {
+	return b.rpzRules.matchIP(ip, groupsToCheck)
+}
+
+// HasRPZTrigger returns true if the response policy zones of the groups have a QNAME or NSDNAME trigger
+func (b *ListCache) HasRPZTrigger(trigger parsers.RPZTrigger, groupsToCheck []string) bool {
+	return b.rpzRules.hasTrigger(trigger, groupsToCheck)
+}
+
+func (b *ListCache) elementCount(group string) int {
+	return b.groupedCache.ElementCount(group) + b.filterRules.elementCount(group) + b.rpzRules.elementCount(group)
 }
 
 // Refresh triggers the refresh of a list
@@ -124,9 +180,9 @@ func (b *ListCache) refresh(ctx context.Context) error {
 		group, sources := group, sources
 
 		unlimitedGrp.Go(func(ctx context.Context) error {
-			err := b.createCacheForGroup(producersGrp, unlimitedGrp, group, sources)
+			err := b.createCacheForGroup(ctx, producersGrp, unlimitedGrp, group, sources)
 			if err != nil {
-				count := b.groupedCache.ElementCount(group)
+				count := b.elementCount(group)
 
 				logger := logger().WithFields(logrus.Fields{
 					"group":       group,
@@ -142,7 +198,7 @@ func (b *ListCache) refresh(ctx context.Context) error {
 				return err
 			}
 
-			count := b.groupedCache.ElementCount(group)
+			count := b.elementCount(group)
 
 			evt.Bus().Publish(evt.BlockingCacheGroupChanged, b.listType, group, count)
 
@@ -158,26 +214,49 @@ func (b *ListCache) refresh(ctx context.Context) error {
 	return unlimitedGrp.Wait()
 }
 
-func (b *ListCache) createCacheForGroup(
+func (b *ListCache) createCacheForGroup(ctx context.Context,
 	producersGrp, consumersGrp jobgroup.JobGroup, group string, sources []config.BytesSource,
 ) error {
+	var downloads map[int]io.ReadCloser
+
+	if b.cfg.Downloads.CacheDir != "" && b.elementCount(group) != 0 {
+		var unchanged bool
+
+		if downloads, unchanged = b.sourcesUnchanged(ctx, group, sources); unchanged {
+			logger().WithField("group", group).Debug("sources not modified, keeping group cache")
+
+			return nil
+		}
+	}
+
 	groupFactory := b.groupedCache.Refresh(group)
+	ruleFactory := b.filterRules.refresh(group)
+	rpzFactory := b.rpzRules.refresh(group)
 
 	producers := parcour.NewProducersWithBuffer[string](producersGrp, consumersGrp, groupProducersBufferCap)
 	defer producers.Close()
 
+	// each producer only writes the version of its own source
+	versions := make([]string, len(sources))
+
 	for i, source := range sources {
 		i, source := i, source
 
 		producers.GoProduce(func(ctx context.Context, hostsChan chan<- string) error {
 			locInfo := fmt.Sprintf("item #%d of group %s", i, group)
 
+			if source.Type == config.BytesSourceTypeHttp {
+				opener := &httpOpener{source: source, downloader: b.downloader, body: downloads[i], version: &versions[i]}
+
+				return b.parseFile(ctx, opener, source.Format, hostsChan)
+			}
+
 			opener, err := NewSourceOpener(locInfo, source, b.downloader)
 			if err != nil {
 				return err
 			}
 
-			return b.parseFile(ctx, opener, hostsChan)
+			return b.parseFile(ctx, opener, source.Format, hostsChan)
 		})
 	}
 
@@ -185,7 +264,7 @@ func (b *ListCache) createCacheForGroup(
 
 	producers.GoConsume(func(ctx context.Context, ch <-chan string) error {
 		for host := range ch {
-			if groupFactory.AddEntry(host) {
+			if rpzFactory.addEntry(host) || ruleFactory.addEntry(host) || groupFactory.AddEntry(host) {
 				hasEntries = true
 			} else {
 				logger().WithField("host", host).Warn("no list cache was able to use host")
@@ -211,12 +290,60 @@ func (b *ListCache) createCacheForGroup(
 	}
 
 	groupFactory.Finish()
+	ruleFactory.finish()
+	rpzFactory.finish()
+
+	b.parsedLock.Lock()
+	b.parsedVersions[group] = versions
+	b.parsedLock.Unlock()
 
 	return nil
 }
 
+// sourcesUnchanged returns true if the group only has downloaded and inline sources, and every download has the
+// version the group was last parsed from. It stops at the first changed source and returns the opened downloads by
+// source index, so parsing the group doesn't download them again.
+func (b *ListCache) sourcesUnchanged(
+	ctx context.Context, group string, sources []config.BytesSource,
+) (downloads map[int]io.ReadCloser, unchanged bool) {
+	b.parsedLock.Lock()
+	parsed := b.parsedVersions[group]
+	b.parsedLock.Unlock()
+
+	downloads = make(map[int]io.ReadCloser)
+
+	for i, source := range sources {
+		switch source.Type {
+		case config.BytesSourceTypeText:
+			continue
+		case config.BytesSourceTypeHttp:
+		default:
+			return downloads, false
+		}
+
+		body, err := b.downloader.DownloadFile(ctx, source.From)
+		if err != nil {
+			return downloads, false
+		}
+
+		downloads[i] = body
+
+		if version := fileVersion(body); version == "" || i >= len(parsed) || parsed[i] != version {
+			return downloads, false
+		}
+	}
+
+	for _, body := range downloads {
+		_ = body.Close()
+	}
+
+	return nil, len(downloads) != 0
+}
+
 // downloads file (or reads local file) and writes each line in the file to the result channel
-func (b *ListCache) parseFile(ctx context.Context, opener SourceOpener, resultCh chan<- string) error {
+func (b *ListCache) parseFile(
+	ctx context.Context, opener SourceOpener, format config.BytesSourceFormat, resultCh chan<- string,
+) error {
 	count := 0
 
 	logger := func() *logrus.Entry {
@@ -236,27 +363,47 @@ func (b *ListCache) parseFile(ctx context.Context, opener SourceOpener, resultCh
 	}
 	defer r.Close()
 
-	p := parsers.AllowErrors(parsers.Hosts(r), b.cfg.MaxErrorsPerSource)
-	p.OnErr(func(err error) {
+	onErr := func(err error) {
 		logger().Warnf("parse error: %s, trying to continue", err)
-	})
+	}
 
-	err = parsers.ForEach[*parsers.HostsIterator](ctx, p, func(hosts *parsers.HostsIterator) error {
-		return hosts.ForEach(func(host string) error {
-			count++
+	switch format {
+	case config.BytesSourceFormatRpz:
+		p := parsers.AllowErrors(parsers.RPZ(r), b.cfg.MaxErrorsPerSource)
+		p.OnErr(onErr)
 
-			// For IPs, we want to ensure the string is the Go representation so that when
-			// we compare responses, a same IP matches, even if it was written differently
-			// in the list.
-			if ip := net.ParseIP(host); ip != nil {
-				host = ip.String()
-			}
+		err = parsers.ForEach[*parsers.RPZRule](ctx, p, func(rule *parsers.RPZRule) error {
+			count++
 
-			resultCh <- host
+			resultCh <- rule.String()
 
 			return nil
 		})
-	})
+
+	default:
+		p := parsers.AllowErrors(hostsParser(format, r), b.cfg.MaxErrorsPerSource)
+		p.OnErr(onErr)
+
+		err = parsers.ForEach[*parsers.HostsIterator](ctx, p, func(hosts *parsers.HostsIterator) error {
+			return hosts.ForEach(func(host string) error {
+				count++
+
+				// For IPs, we want to ensure the string is the Go representation so that when
+				// we compare responses, a same IP matches, even if it was written differently
+				// in the list.
+				if ip := net.ParseIP(host); ip != nil {
+					host = ip.String()
+				} else if prefix, err := netip.ParsePrefix(host); err == nil {
+					host = prefix.Masked().String()
+				}
+
+				resultCh <- host
+
+				return nil
+			})
+		})
+	}
+
 	if err != nil {
 		// Don't log cancelation: it was caused by another goroutine failing
 		if !errors.Is(err, context.Canceled) {
@@ -277,3 +424,17 @@ func (b *ListCache) parseFile(ctx context.Context, opener SourceOpener, resultCh
 
 	return nil
 }
+
+// hostsParser returns the parser of a list format, the hosts format detects the other line formats
+func hostsParser(format config.BytesSourceFormat, r io.Reader) parsers.SeriesParser[*parsers.HostsIterator] {
+	switch format {
+	case config.BytesSourceFormatDnsmasq:
+		return parsers.Dnsmasq(r)
+	case config.BytesSourceFormatUnbound:
+		return parsers.Unbound(r)
+	case config.BytesSourceFormatRegex:
+		return parsers.Regexes(r)
+	default:
+		return parsers.Hosts(r)
+	}
+}
@@ -0,0 +1,231 @@
+package parsers
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// AdblockRule is a DNS filter rule in the adblock syntax used by AdGuard and uBlock lists,
+// e.g. `||example.com^`, `@@||example.com^` or `||example.com^$important,dnstype=AAAA`.
+type AdblockRule struct {
+	// Domain the rule applies to, in lower case
+	Domain string
+	// Exact rules (`|example.com^`) don't apply to subdomains
+	Exact bool
+	// Exception rules (`@@`) unblock the domain
+	Exception bool
+	// Important rules take precedence over exceptions without `$important`
+	Important bool
+	// BadFilter rules disable the rule with the same text without `$badfilter`
+	BadFilter bool
+	// Clients the rule applies to: names, IPs or subnets, a `~` prefix excludes the client
+	Clients []string
+	// DNSTypes the rule applies to, e.g. AAAA, a `~` prefix excludes the type
+	DNSTypes []string
+}
+
+// String returns the rule in a normalized form with sorted modifiers.
+func (r AdblockRule) String() string {
+	var sb strings.Builder
+
+	if r.Exception {
+		sb.WriteString("@@")
+	}
+
+	if r.Exact {
+		sb.WriteString("|")
+	} else {
+		sb.WriteString("||")
+	}
+
+	sb.WriteString(r.Domain)
+	sb.WriteString("^")
+
+	var modifiers []string
+
+	if r.BadFilter {
+		modifiers = append(modifiers, "badfilter")
+	}
+
+	if len(r.Clients) != 0 {
+		clients := make([]string, 0, len(r.Clients))
+
+		for _, client := range r.Clients {
+			clients = append(clients, quoteClient(client))
+		}
+
+		modifiers = append(modifiers, "client="+strings.Join(clients, "|"))
+	}
+
+	if len(r.DNSTypes) != 0 {
+		modifiers = append(modifiers, "dnstype="+strings.Join(r.DNSTypes, "|"))
+	}
+
+	if r.Important {
+		modifiers = append(modifiers, "important")
+	}
+
+	if len(modifiers) != 0 {
+		sb.WriteString("$")
+		sb.WriteString(strings.Join(modifiers, ","))
+	}
+
+	return sb.String()
+}
+
+// We assume this is used with `Lines`:
+// - data will never be empty
+// - comments are stripped
+func (r *AdblockRule) UnmarshalText(data []byte) error {
+	text := string(data)
+
+	var rule AdblockRule
+
+	if after, ok := strings.CutPrefix(text, "@@"); ok {
+		rule.Exception = true
+		text = after
+	}
+
+	pattern, modifiers, hasModifiers := strings.Cut(text, "$")
+
+	switch {
+	case strings.HasPrefix(pattern, "||"):
+		pattern = pattern[2:]
+	case strings.HasPrefix(pattern, "|"):
+		rule.Exact = true
+		pattern = pattern[1:]
+	default:
+		return fmt.Errorf("unsupported adblock rule '%s': must start with '||' or '|'", string(data))
+	}
+
+	pattern = strings.TrimSuffix(strings.TrimSuffix(pattern, "|"), "^")
+
+	domain, err := normalizeHostsListEntry(strings.ToLower(pattern))
+	if err != nil {
+		return err
+	}
+
+	if err := validateDomainName(domain); err != nil {
+		return err
+	}
+
+	rule.Domain = strings.TrimSuffix(domain, ".")
+
+	if hasModifiers {
+		for _, modifier := range splitUnquoted(modifiers, ',') {
+			if err := rule.parseModifier(strings.TrimSpace(modifier)); err != nil {
+				return fmt.Errorf("%w in adblock rule '%s'", err, string(data))
+			}
+		}
+	}
+
+	*r = rule
+
+	return nil
+}
+
+func (r *AdblockRule) parseModifier(modifier string) error {
+	name, value, _ := strings.Cut(modifier, "=")
+
+	switch name {
+	case "important":
+		r.Important = true
+	case "badfilter":
+		r.BadFilter = true
+	case "client":
+		for _, client := range splitUnquoted(value, '|') {
+			r.Clients = append(r.Clients, unquoteClient(client))
+		}
+
+		if len(r.Clients) == 0 {
+			return fmt.Errorf("no client in modifier '%s'", modifier)
+		}
+
+		slices.Sort(r.Clients)
+	case "dnstype":
+		for _, qType := range splitUnquoted(value, '|') {
+			qType = strings.ToUpper(qType)
+
+			if _, ok := dns.StringToType[strings.TrimPrefix(qType, "~")]; !ok {
+				return fmt.Errorf("unknown DNS type '%s'", qType)
+			}
+
+			r.DNSTypes = append(r.DNSTypes, qType)
+		}
+
+		if len(r.DNSTypes) == 0 {
+			return fmt.Errorf("no DNS type in modifier '%s'", modifier)
+		}
+
+		slices.Sort(r.DNSTypes)
+	default:
+		return fmt.Errorf("unsupported modifier '%s'", modifier)
+	}
+
+	return nil
+}
+
+// splitUnquoted splits the modifiers or the values of a modifier, a separator within quotes doesn't split
+func splitUnquoted(value string, sep rune) []string {
+	var (
+		values []string
+		quote  rune
+		start  int
+	)
+
+	for i, c := range value {
+		switch {
+		case quote != 0:
+			if c == quote && value[i-1] != '\\' {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == sep:
+			values = append(values, value[start:i])
+			start = i + 1
+		}
+	}
+
+	values = append(values, value[start:])
+
+	return slices.DeleteFunc(values, func(v string) bool { return strings.TrimSpace(v) == "" })
+}
+
+// unquoteClient removes the quotes of a client name like `'Frank\'s laptop'`, keeping a `~` prefix
+func unquoteClient(client string) string {
+	client = strings.TrimSpace(client)
+	negation, name := "", client
+
+	if strings.HasPrefix(client, "~") {
+		negation, name = "~", client[1:]
+	}
+
+	if len(name) >= 2 && (name[0] == '\'' || name[0] == '"') && name[len(name)-1] == name[0] {
+		name = strings.ReplaceAll(name[1:len(name)-1], `\`+name[:1], name[:1])
+	}
+
+	return negation + name
+}
+
+// quoteClient quotes a client name containing characters with a meaning in a rule
+func quoteClient(client string) string {
+	negation, name := "", client
+
+	if strings.HasPrefix(client, "~") {
+		negation, name = "~", client[1:]
+	}
+
+	if !strings.ContainsAny(name, `|,$'" `) {
+		return client
+	}
+
+	return negation + "'" + strings.ReplaceAll(name, "'", `\'`) + "'"
+}
+
+func (r AdblockRule) forEachHost(callback func(string) error) error {
+	return callback(r.String())
+}
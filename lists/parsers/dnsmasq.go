@@ -0,0 +1,91 @@
+package parsers
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strings"
+)
+
+// Dnsmasq parses `r` as a series of `HostsIterator` of `DnsmasqEntry`.
+//
+// This is for block lists in the dnsmasq configuration format.
+func Dnsmasq(r io.Reader) SeriesParser[*HostsIterator] {
+	return Adapt(LinesAs[*DnsmasqEntry](r), func(e *DnsmasqEntry) *HostsIterator {
+		return &HostsIterator{e}
+	})
+}
+
+// DnsmasqEntry is a dnsmasq `address=/example.com/`, `server=/example.com/` or `local=/example.com/` line,
+// which blocks the domains and their subdomains.
+//
+// The `#` target means NXDOMAIN for `address=/example.com/#`, which blocks too. For `server=/example.com/#` and
+// `local=/example.com/#` it means resolving with the standard servers: lists use them as exceptions, they don't block
+// anything.
+type DnsmasqEntry struct {
+	Domains []string
+}
+
+// We assume this is used with `Lines`:
+// - data will never be empty
+// - comments are stripped, but `Lines` keeps the `#` target of an option
+func (e *DnsmasqEntry) UnmarshalText(data []byte) error {
+	key, value, found := strings.Cut(string(data), "=")
+	if !found {
+		return fmt.Errorf("unsupported dnsmasq line '%s': expected 'address=', 'server=' or 'local='", string(data))
+	}
+
+	key = strings.TrimSpace(key)
+
+	switch key {
+	case "address", "server", "local":
+	default:
+		return fmt.Errorf("unsupported dnsmasq option '%s'", key)
+	}
+
+	parts := strings.Split(strings.TrimSpace(value), "/")
+
+	const minParts = 3 // empty, domain, target
+	if len(parts) < minParts || parts[0] != "" {
+		return fmt.Errorf("invalid dnsmasq line '%s': expected '%s=/domain/'", string(data), key)
+	}
+
+	switch target := parts[len(parts)-1]; {
+	case target == "#" && key != "address":
+		// resolved with the standard servers
+		*e = DnsmasqEntry{}
+
+		return nil
+	case target == "#" || target == "":
+	case key != "address":
+		// forwards the domains to a server, e.g. `server=/example.com/192.168.0.1`
+		return fmt.Errorf("unsupported dnsmasq line '%s': forwarding to '%s'", string(data), target)
+	case net.ParseIP(target) == nil:
+		return fmt.Errorf("invalid dnsmasq address '%s'", target)
+	}
+
+	domains := make([]string, 0, len(parts)-minParts+1)
+
+	for _, domain := range parts[1 : len(parts)-1] {
+		domain, err := normalizeListDomain(domain)
+		if err != nil {
+			return err
+		}
+
+		domains = append(domains, domain)
+	}
+
+	*e = DnsmasqEntry{Domains: domains}
+
+	return nil
+}
+
+func (e DnsmasqEntry) forEachHost(callback func(string) error) error {
+	for _, domain := range e.Domains {
+		if err := callback("*." + domain); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
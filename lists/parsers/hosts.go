@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"net/netip"
 	"regexp"
 	"strings"
 
@@ -27,7 +28,8 @@ const (
 var domainNameRegex = regexp.MustCompile(`^` + dnsLabelPattern + `(\.` + dnsLabelPattern + `)*[\._]?$`)
 
 // Hosts parses `r` as a series of `HostsIterator`.
-// It supports both the hosts file and host list formats.
+// It supports the hosts file and host list formats, the DNS filter rules of adblock lists, and
+// dnsmasq and unbound lines.
 //
 // Each item being an iterator was chosen to abstract the difference between the
 // two formats where each host list entry is a single host, but a hosts file
@@ -58,6 +60,9 @@ func (h *HostsIterator) UnmarshalText(data []byte) error {
 		new(HostListEntry),
 		new(HostsFileEntry),
 		new(WildcardEntry),
+		new(AdblockRule),
+		new(DnsmasqEntry),
+		new(UnboundEntry),
 	}
 
 	for _, entry := range entries {
@@ -275,6 +280,12 @@ func validateHostsListEntry(host string) error {
 		return nil
 	}
 
+	if strings.Contains(host, "/") && !isRegex(host) {
+		_, err := netip.ParsePrefix(host)
+
+		return err
+	}
+
 	if isRegex(host) {
 		_, err := regexp.Compile(host)
 
@@ -283,3 +294,22 @@ func validateHostsListEntry(host string) error {
 
 	return validateDomainName(host)
 }
+
+// normalizeListDomain returns the domain of a dnsmasq or unbound entry in lower case and without trailing dot
+func normalizeListDomain(domain string) (string, error) {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	if domain == "" {
+		return "", fmt.Errorf("empty domain name")
+	}
+
+	domain, err := idna.Punycode.ToASCII(domain)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, domain)
+	}
+
+	if err := validateDomainName(domain); err != nil {
+		return "", err
+	}
+
+	return domain, nil
+}
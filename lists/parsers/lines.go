@@ -13,6 +13,7 @@ import (
 // Lines splits `r` into a series of lines.
 //
 // Empty lines are skipped, and comments are stripped.
+// Adblock comments (`!`), headers and cosmetic rules (e.g. `example.com##.banner`) are skipped as well.
 func Lines(r io.Reader) SeriesParser[string] {
 	return newLines(r)
 }
@@ -70,11 +71,23 @@ func (l *lines) Next(ctx context.Context) (string, error) {
 			continue // empty line
 		}
 
+		if text[0] == '!' || (text[0] == '[' && text[len(text)-1] == ']') {
+			continue // adblock comment or header like `[Adblock Plus 2.0]`
+		}
+
 		if idx := strings.IndexRune(text, '#'); idx != -1 {
 			if idx == 0 {
 				continue // commented line
 			}
 
+			if isCosmeticRule(text, idx) {
+				continue // only applies to web pages
+			}
+
+			if isDnsmasqTarget(text, idx) {
+				return text, nil // `#` is the target of the option
+			}
+
 			// end of line comment
 			text = text[:idx]
 			text = strings.TrimRightFunc(text, unicode.IsSpace)
@@ -91,3 +104,28 @@ func (l *lines) Next(ctx context.Context) (string, error) {
 
 	return "", NewNonResumableError(io.EOF)
 }
+
+// isCosmeticRule returns true if the '#' at idx starts an adblock element hiding or scriptlet marker
+// like `##`, `#@#` or `#$#`, which directly follows the domains of the rule
+func isCosmeticRule(text string, idx int) bool {
+	if unicode.IsSpace(rune(text[idx-1])) || idx+1 >= len(text) {
+		return false
+	}
+
+	return strings.ContainsRune("#@?$%", rune(text[idx+1]))
+}
+
+// isDnsmasqTarget returns true if the '#' at idx is the target of a dnsmasq option like `server=/example.com/#`
+func isDnsmasqTarget(text string, idx int) bool {
+	if idx != len(text)-1 || text[idx-1] != '/' {
+		return false
+	}
+
+	for _, prefix := range []string{"address=", "server=", "local="} {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
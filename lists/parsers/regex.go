@@ -0,0 +1,53 @@
+package parsers
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Regexes parses `r` as a series of `HostsIterator` of `RegexEntry`.
+//
+// This is for regex lists like the ones of Pi-hole, with one regular expression per line.
+func Regexes(r io.Reader) SeriesParser[*HostsIterator] {
+	return Adapt(LinesAs[*RegexEntry](r), func(e *RegexEntry) *HostsIterator {
+		return &HostsIterator{e}
+	})
+}
+
+// RegexEntry is a regular expression without surrounding slashes, e.g. `(^|\.)doubleclick\.net$`.
+type RegexEntry string
+
+func (e RegexEntry) String() string {
+	return string(e)
+}
+
+// We assume this is used with `Lines`:
+// - data will never be empty
+// - comments are stripped
+func (e *RegexEntry) UnmarshalText(data []byte) error {
+	expr := string(data)
+
+	if idx := strings.LastIndexByte(expr, ';'); idx != -1 {
+		option := expr[idx+1:]
+
+		for _, name := range []string{"querytype=", "invert", "reply="} {
+			if strings.HasPrefix(option, name) {
+				return fmt.Errorf("unsupported Pi-hole regex option '%s'", option)
+			}
+		}
+	}
+
+	if _, err := regexp.Compile(expr); err != nil {
+		return err
+	}
+
+	*e = RegexEntry(expr)
+
+	return nil
+}
+
+func (e RegexEntry) forEachHost(callback func(string) error) error {
+	return callback("/" + e.String() + "/")
+}
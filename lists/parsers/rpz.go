@@ -0,0 +1,256 @@
+package parsers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// the origin of relative names in a zone file without `$ORIGIN`
+const defaultRPZOrigin = "rpz."
+
+// RPZTrigger is what a rule of a response policy zone matches
+type RPZTrigger string
+
+const (
+	// RPZTriggerQName matches the query name
+	RPZTriggerQName RPZTrigger = "qname"
+	// RPZTriggerIP matches the IPs of the answer
+	RPZTriggerIP RPZTrigger = "rpz-ip"
+	// RPZTriggerNSDName matches the names of the authoritative name servers of the query name
+	RPZTriggerNSDName RPZTrigger = "rpz-nsdname"
+)
+
+// RPZAction is the policy a rule of a response policy zone applies to matching queries
+type RPZAction string
+
+const (
+	RPZActionNXDomain RPZAction = "nxdomain"
+	RPZActionNoData   RPZAction = "nodata"
+	// RPZActionPassthru exempts the query from all other policies and denylists
+	RPZActionPassthru RPZAction = "passthru"
+	// RPZActionDrop doesn't answer the query
+	RPZActionDrop RPZAction = "drop"
+	// RPZActionCNAME answers with a CNAME to Target (local data)
+	RPZActionCNAME RPZAction = "cname"
+)
+
+// RPZRule is a rule of a response policy zone.
+type RPZRule struct {
+	// Policy is the name of the zone, e.g. rpz.example.com
+	Policy  string
+	Trigger RPZTrigger
+	// Name is the domain for QNAME and NSDNAME triggers, which may start with `*.` to match subdomains,
+	// or the IP prefix for RPZ-IP triggers
+	Name   string
+	Action RPZAction
+	// Target of the CNAME action
+	Target string
+}
+
+// String returns the rule as single line, which is parsed by `UnmarshalText`.
+func (r RPZRule) String() string {
+	res := fmt.Sprintf("rpz %s %s %s %s", r.Policy, r.Trigger, r.Name, r.Action)
+
+	if r.Action == RPZActionCNAME {
+		res += " " + r.Target
+	}
+
+	return res
+}
+
+// UnmarshalText parses a rule returned by `String`.
+func (r *RPZRule) UnmarshalText(data []byte) error {
+	fields := strings.Fields(string(data))
+
+	const minFields = 5
+	if len(fields) < minFields || fields[0] != "rpz" {
+		return fmt.Errorf("invalid RPZ rule: %s", string(data))
+	}
+
+	rule := RPZRule{
+		Policy:  fields[1],
+		Trigger: RPZTrigger(fields[2]),
+		Name:    fields[3],
+		Action:  RPZAction(fields[4]),
+	}
+
+	if !slices.Contains([]RPZTrigger{RPZTriggerQName, RPZTriggerIP, RPZTriggerNSDName}, rule.Trigger) {
+		return fmt.Errorf("unknown RPZ trigger '%s'", rule.Trigger)
+	}
+
+	switch rule.Action {
+	case RPZActionNXDomain, RPZActionNoData, RPZActionPassthru, RPZActionDrop:
+	case RPZActionCNAME:
+		if len(fields) <= minFields {
+			return fmt.Errorf("RPZ rule without CNAME target: %s", string(data))
+		}
+
+		rule.Target = fields[minFields]
+	default:
+		return fmt.Errorf("unknown RPZ action '%s'", rule.Action)
+	}
+
+	*r = rule
+
+	return nil
+}
+
+// RPZ parses `r` as a response policy zone in the zone file format, e.g. the records of a zone transfer.
+//
+// The policy name is the name of the zone (SOA record), relative names of a file without `$ORIGIN` are
+// relative to `rpz`.
+func RPZ(r io.Reader) SeriesParser[*RPZRule] {
+	return &rpzParser{
+		zp:     dns.NewZoneParser(r, defaultRPZOrigin, ""),
+		policy: defaultRPZOrigin,
+	}
+}
+
+type rpzParser struct {
+	zp      *dns.ZoneParser
+	policy  string
+	records uint
+}
+
+func (p *rpzParser) Position() string {
+	return fmt.Sprintf("record %d", p.records)
+}
+
+func (p *rpzParser) Next(ctx context.Context) (*RPZRule, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, NewNonResumableError(err)
+		}
+
+		rr, ok := p.zp.Next()
+		if !ok {
+			if err := p.zp.Err(); err != nil {
+				// dns.ZoneParser does not support continuing after an error
+				return nil, NewNonResumableError(err)
+			}
+
+			return nil, NewNonResumableError(io.EOF)
+		}
+
+		p.records++
+
+		hdr := rr.Header()
+
+		switch {
+		case hdr.Rrtype == dns.TypeSOA:
+			p.policy = strings.ToLower(hdr.Name)
+
+			continue
+		case hdr.Rrtype == dns.TypeNS && strings.EqualFold(hdr.Name, p.policy):
+			continue
+		}
+
+		return newRPZRule(p.policy, rr)
+	}
+}
+
+func newRPZRule(policy string, rr dns.RR) (*RPZRule, error) {
+	owner := strings.ToLower(rr.Header().Name)
+
+	if owner == policy || !dns.IsSubDomain(policy, owner) {
+		return nil, fmt.Errorf("record '%s' is not within the policy zone '%s'", owner, policy)
+	}
+
+	name := owner[:len(owner)-len(policy)-1]
+	rule := RPZRule{Policy: strings.TrimSuffix(policy, "."), Trigger: RPZTriggerQName, Name: name}
+
+	switch {
+	case strings.HasSuffix(name, ".rpz-ip"):
+		prefix, err := parseRPZIP(strings.TrimSuffix(name, ".rpz-ip"))
+		if err != nil {
+			return nil, err
+		}
+
+		rule.Trigger = RPZTriggerIP
+		rule.Name = prefix.String()
+	case strings.HasSuffix(name, ".rpz-nsdname"):
+		rule.Trigger = RPZTriggerNSDName
+		rule.Name = strings.TrimSuffix(name, ".rpz-nsdname")
+	case strings.HasSuffix(name, ".rpz-client-ip"), strings.HasSuffix(name, ".rpz-nsip"):
+		return nil, fmt.Errorf("unsupported RPZ trigger '%s'", name)
+	}
+
+	cname, ok := rr.(*dns.CNAME)
+	if !ok {
+		return nil, fmt.Errorf("unsupported local data '%s' of '%s': only CNAME is supported",
+			dns.TypeToString[rr.Header().Rrtype], name)
+	}
+
+	switch target := strings.ToLower(cname.Target); target {
+	case ".":
+		rule.Action = RPZActionNXDomain
+	case "*.":
+		rule.Action = RPZActionNoData
+	case "rpz-passthru.":
+		rule.Action = RPZActionPassthru
+	case "rpz-drop.":
+		rule.Action = RPZActionDrop
+	default:
+		if strings.HasPrefix(target, "*.") || strings.HasPrefix(target, "rpz-") {
+			return nil, fmt.Errorf("unsupported RPZ action '%s' of '%s'", target, name)
+		}
+
+		rule.Action = RPZActionCNAME
+		rule.Target = strings.TrimSuffix(target, ".")
+	}
+
+	return &rule, nil
+}
+
+// parseRPZIP parses the prefix length and the labels of the reversed IP of an RPZ-IP trigger,
+// e.g. `24.0.2.0.192` or `48.zz.db8.2001`, where `zz` replaces `::`
+func parseRPZIP(name string) (netip.Prefix, error) {
+	const minLabels = 2
+
+	labels := strings.Split(name, ".")
+
+	bits, err := strconv.Atoi(labels[0])
+	if err != nil || len(labels) < minLabels {
+		return netip.Prefix{}, fmt.Errorf("invalid RPZ-IP trigger '%s'", name)
+	}
+
+	parts := labels[1:]
+	slices.Reverse(parts)
+
+	var text string
+
+	const ipv4Labels = 4
+	if len(parts) == ipv4Labels && !slices.Contains(parts, "zz") {
+		text = strings.Join(parts, ".")
+	} else {
+		text = strings.Replace(strings.Join(parts, ":"), "zz", "", 1)
+
+		switch {
+		case text == "":
+			text = "::"
+		case strings.HasPrefix(text, ":") && !strings.HasPrefix(text, "::"):
+			text = ":" + text
+		case strings.HasSuffix(text, ":") && !strings.HasSuffix(text, "::"):
+			text += ":"
+		}
+	}
+
+	addr, err := netip.ParseAddr(text)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid RPZ-IP trigger '%s': %w", name, err)
+	}
+
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid RPZ-IP trigger '%s': %w", name, err)
+	}
+
+	return prefix, nil
+}
@@ -0,0 +1,111 @@
+package parsers
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Unbound parses `r` as a series of `HostsIterator` of `UnboundEntry`.
+//
+// This is for block lists in the unbound configuration format.
+func Unbound(r io.Reader) SeriesParser[*HostsIterator] {
+	return Adapt(LinesAs[*UnboundEntry](r), func(e *UnboundEntry) *HostsIterator {
+		return &HostsIterator{e}
+	})
+}
+
+// UnboundEntry is an unbound `local-zone:` or `local-data:` statement.
+//
+// A local zone of a blocking type like `always_nxdomain` blocks the domain and its subdomains, local data blocks the
+// name of the record. A `server:` clause and local zones of other types, e.g. `transparent`, don't block anything.
+type UnboundEntry struct {
+	Domain string
+	// Zone entries apply to subdomains
+	Zone bool
+}
+
+// local zone types, true if the type answers queries with local data or not at all instead of resolving them
+var unboundBlockingZoneTypes = map[string]bool{ //nolint:gochecknoglobals
+	"deny": true, "refuse": true, "static": true, "redirect": true, "noview": true,
+	"inform_deny": true, "inform_redirect": true, "always_deny": true, "always_refuse": true,
+	"always_nxdomain": true, "always_nodata": true, "always_null": true,
+
+	"transparent": false, "typetransparent": false, "always_transparent": false,
+	"inform": false, "block_a": false, "nodefault": false,
+}
+
+// We assume this is used with `Lines`:
+// - data will never be empty
+// - comments are stripped
+func (e *UnboundEntry) UnmarshalText(data []byte) error {
+	key, value, found := strings.Cut(string(data), ":")
+	if !found {
+		return fmt.Errorf("unsupported unbound line '%s': expected 'local-zone:' or 'local-data:'", string(data))
+	}
+
+	value = strings.TrimSpace(value)
+
+	switch strings.TrimSpace(key) {
+	case "server":
+		if value != "" {
+			return fmt.Errorf("unexpected value after 'server:': %s", value)
+		}
+
+		*e = UnboundEntry{}
+
+	case "local-zone":
+		fields := strings.Fields(value)
+
+		const zoneFields = 2
+		if len(fields) != zoneFields {
+			return fmt.Errorf("invalid unbound line '%s': expected 'local-zone: \"domain\" type'", string(data))
+		}
+
+		blocking, ok := unboundBlockingZoneTypes[fields[1]]
+		if !ok {
+			return fmt.Errorf("unknown unbound local zone type '%s'", fields[1])
+		}
+
+		domain, err := normalizeListDomain(strings.Trim(fields[0], `"`))
+		if err != nil {
+			return err
+		}
+
+		*e = UnboundEntry{Zone: true}
+
+		if blocking {
+			e.Domain = domain
+		}
+
+	case "local-data":
+		// the record, e.g. `"ads.example.com A 0.0.0.0"`
+		fields := strings.Fields(strings.Trim(value, `"'`))
+		if len(fields) == 0 {
+			return fmt.Errorf("invalid unbound line '%s': expected 'local-data: \"domain type data\"'", string(data))
+		}
+
+		domain, err := normalizeListDomain(fields[0])
+		if err != nil {
+			return err
+		}
+
+		*e = UnboundEntry{Domain: domain}
+
+	default:
+		return fmt.Errorf("unsupported unbound statement '%s'", strings.TrimSpace(key))
+	}
+
+	return nil
+}
+
+func (e UnboundEntry) forEachHost(callback func(string) error) error {
+	switch {
+	case e.Domain == "":
+		return nil
+	case e.Zone:
+		return callback("*." + e.Domain)
+	default:
+		return callback(e.Domain)
+	}
+}
@@ -0,0 +1,181 @@
+package lists
+
+import (
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+
+	"github.com/Abiji-2020/bGuard/lists/parsers"
+	"github.com/Abiji-2020/bGuard/trie"
+)
+
+// RPZMatch is the rule of a response policy zone matching a query
+type RPZMatch struct {
+	parsers.RPZRule
+	// Group is the list group of the zone
+	Group string
+}
+
+// rpzRules keeps the rules of the response policy zones of the groups
+type rpzRules struct {
+	lock   sync.RWMutex
+	groups map[string]*rpzRuleGroup
+}
+
+type rpzRuleGroup struct {
+	// QNAME and NSDNAME rules by trigger and name, wildcards by their parent domain
+	names     map[parsers.RPZTrigger]map[string]parsers.RPZRule
+	wildcards map[parsers.RPZTrigger]map[string]parsers.RPZRule
+	ips       *trie.IPTrie[parsers.RPZRule]
+	count     int
+}
+
+func newRPZRules() *rpzRules {
+	return &rpzRules{groups: make(map[string]*rpzRuleGroup)}
+}
+
+func (c *rpzRules) elementCount(group string) int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if g, ok := c.groups[group]; ok {
+		return g.count
+	}
+
+	return 0
+}
+
+// hasTrigger returns true if one of the groups has a rule with the trigger
+func (c *rpzRules) hasTrigger(trigger parsers.RPZTrigger, groupsToCheck []string) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for _, group := range groupsToCheck {
+		if g, ok := c.groups[group]; ok && (len(g.names[trigger]) != 0 || len(g.wildcards[trigger]) != 0) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// refresh returns a factory to replace the rules of the group
+func (c *rpzRules) refresh(group string) *rpzRuleFactory {
+	return &rpzRuleFactory{
+		rules: &rpzRuleGroup{
+			names:     make(map[parsers.RPZTrigger]map[string]parsers.RPZRule),
+			wildcards: make(map[parsers.RPZTrigger]map[string]parsers.RPZRule),
+			ips:       trie.NewIPTrie[parsers.RPZRule](),
+		},
+		finishFn: func(g *rpzRuleGroup) {
+			c.lock.Lock()
+			defer c.lock.Unlock()
+
+			if g.count != 0 {
+				c.groups[group] = g
+			} else {
+				delete(c.groups, group)
+			}
+		},
+	}
+}
+
+type rpzRuleFactory struct {
+	rules    *rpzRuleGroup
+	finishFn func(*rpzRuleGroup)
+}
+
+// addEntry adds the entry, if it is an RPZ rule. The first rule for a name wins, like the first matching zone.
+func (f *rpzRuleFactory) addEntry(entry string) bool {
+	if !strings.HasPrefix(entry, "rpz ") {
+		return false
+	}
+
+	var rule parsers.RPZRule
+
+	if err := rule.UnmarshalText([]byte(entry)); err != nil {
+		return false
+	}
+
+	if rule.Trigger == parsers.RPZTriggerIP {
+		prefix, err := netip.ParsePrefix(rule.Name)
+		if err != nil {
+			return false
+		}
+
+		if _, exists := f.rules.ips.Get(prefix); !exists {
+			f.rules.ips.Insert(prefix, rule)
+		}
+	} else {
+		rules := f.rules.names
+
+		name := rule.Name
+		if parent, ok := strings.CutPrefix(name, "*."); ok {
+			rules, name = f.rules.wildcards, parent
+		}
+
+		if rules[rule.Trigger] == nil {
+			rules[rule.Trigger] = make(map[string]parsers.RPZRule)
+		}
+
+		if _, exists := rules[rule.Trigger][name]; !exists {
+			rules[rule.Trigger][name] = rule
+		}
+	}
+
+	f.rules.count++
+
+	return true
+}
+
+func (f *rpzRuleFactory) finish() {
+	f.finishFn(f.rules)
+}
+
+// matchName returns the rule of the first group with a rule for the name: an exact rule or
+// the wildcard of the closest parent domain
+func (c *rpzRules) matchName(trigger parsers.RPZTrigger, name string, groupsToCheck []string) (*RPZMatch, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for _, group := range groupsToCheck {
+		g, ok := c.groups[group]
+		if !ok {
+			continue
+		}
+
+		if rule, ok := g.names[trigger][name]; ok {
+			return &RPZMatch{RPZRule: rule, Group: group}, true
+		}
+
+		for parent := name; strings.Contains(parent, "."); {
+			parent = parent[strings.IndexByte(parent, '.')+1:]
+
+			if rule, ok := g.wildcards[trigger][parent]; ok {
+				return &RPZMatch{RPZRule: rule, Group: group}, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// matchIP returns the rule of the first group with the longest prefix containing the IP
+func (c *rpzRules) matchIP(ip net.IP, groupsToCheck []string) (*RPZMatch, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for _, group := range groupsToCheck {
+		g, ok := c.groups[group]
+		if !ok {
+			continue
+		}
+
+		if rule, ok := g.ips.LongestMatch(ip); ok {
+			return &RPZMatch{RPZRule: rule, Group: group}, true
+		}
+	}
+
+	return nil, false
+}
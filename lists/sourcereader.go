@@ -1,13 +1,17 @@
 package lists
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"strings"
 
 	"github.com/Abiji-2020/bGuard/config"
+
+	"github.com/miekg/dns"
 )
 
 type SourceOpener interface {
@@ -26,6 +30,9 @@ func NewSourceOpener(txtLocInfo string, source config.BytesSource, downloader Fi
 
 	case config.BytesSourceTypeFile:
 		return &fileOpener{source: source}, nil
+
+	case config.BytesSourceTypeAxfr:
+		return &axfrOpener{source: source}, nil
 	}
 
 	return nil, fmt.Errorf("cannot open %s", source)
@@ -47,10 +54,38 @@ func (o *textOpener) String() string {
 type httpOpener struct {
 	source     config.BytesSource
 	downloader FileDownloader
+
+	// optional: the already downloaded body
+	body io.ReadCloser
+	// optional: receives the version of the downloaded file
+	version *string
 }
 
 func (o *httpOpener) Open(ctx context.Context) (io.ReadCloser, error) {
-	return o.downloader.DownloadFile(ctx, o.source.From)
+	body := o.body
+	if body == nil {
+		var err error
+
+		body, err = o.downloader.DownloadFile(ctx, o.source.From)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if o.version != nil {
+		*o.version = fileVersion(body)
+	}
+
+	return decompress(o.source, body)
+}
+
+// fileVersion returns the version of a downloaded file, or an empty string if it is unknown
+func fileVersion(body io.ReadCloser) string {
+	if file, ok := body.(interface{ Version() string }); ok {
+		return file.Version()
+	}
+
+	return ""
 }
 
 func (o *httpOpener) String() string {
@@ -62,9 +97,57 @@ type fileOpener struct {
 }
 
 func (o *fileOpener) Open(_ context.Context) (io.ReadCloser, error) {
-	return os.Open(o.source.From)
+	file, err := os.Open(o.source.From)
+	if err != nil {
+		return nil, err
+	}
+
+	return decompress(o.source, file)
 }
 
 func (o *fileOpener) String() string {
 	return o.source.String()
 }
+
+// axfrOpener transfers the zone from a name server, `From` is `host[:port]/zone`
+type axfrOpener struct {
+	source config.BytesSource
+}
+
+func (o *axfrOpener) Open(_ context.Context) (io.ReadCloser, error) {
+	server, zone, found := strings.Cut(o.source.From, "/")
+	if !found || zone == "" {
+		return nil, fmt.Errorf("invalid zone transfer source '%s': expected host[:port]/zone", o.source.From)
+	}
+
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+
+	msg := new(dns.Msg)
+	msg.SetAxfr(dns.Fqdn(zone))
+
+	envelopes, err := new(dns.Transfer).In(msg, server)
+	if err != nil {
+		return nil, fmt.Errorf("zone transfer of '%s' failed: %w", zone, err)
+	}
+
+	var buf bytes.Buffer
+
+	for envelope := range envelopes {
+		if envelope.Error != nil {
+			return nil, fmt.Errorf("zone transfer of '%s' failed: %w", zone, envelope.Error)
+		}
+
+		for _, rr := range envelope.RR {
+			buf.WriteString(rr.String())
+			buf.WriteString("\n")
+		}
+	}
+
+	return io.NopCloser(&buf), nil
+}
+
+func (o *axfrOpener) String() string {
+	return o.source.String()
+}
@@ -51,6 +51,26 @@ func registerBlockingEventListeners() {
 		}
 	})
 
+	scheduleActive := scheduleActiveGauge()
+
+	RegisterMetric(scheduleActive)
+
+	subscribe(evt.BlockingScheduleChanged, func(schedule string, active bool) {
+		if active {
+			scheduleActive.WithLabelValues(schedule).Set(1)
+		} else {
+			scheduleActive.WithLabelValues(schedule).Set(0)
+		}
+	})
+
+	geoIPMatches := geoIPMatchesCounter()
+
+	RegisterMetric(geoIPMatches)
+
+	subscribe(evt.BlockingGeoIPMatched, func(country, action string) {
+		geoIPMatches.WithLabelValues(country, action).Inc()
+	})
+
 	denylistCnt := denylistGauge()
 
 	allowlistCnt := allowlistGauge()
@@ -83,6 +103,24 @@ func enabledGauge() prometheus.Gauge {
 	return enabledGauge
 }
 
+func scheduleActiveGauge() *prometheus.GaugeVec {
+	return prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bGuard_blocking_schedule_active",
+			Help: "Blocking schedule status (1 = within a window)",
+		}, []string{"schedule"},
+	)
+}
+
+func geoIPMatchesCounter() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bGuard_geoip_matches_total",
+			Help: "Number of responses blocked or flagged by the country of an answer IP",
+		}, []string{"country", "action"},
+	)
+}
+
 func denylistGauge() *prometheus.GaugeVec {
 	denylistCnt := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -30,6 +30,8 @@ func (t ResponseType) ToExtendedErrorCode() uint16 {
 		return dns.ExtendedErrorCodeFiltered
 	case ResponseTypeSPECIAL:
 		return dns.ExtendedErrorCodeFiltered
+	case ResponseTypeSAFESEARCH:
+		return dns.ExtendedErrorCodeFiltered
 	default:
 		return dns.ExtendedErrorCodeOther
 	}
@@ -40,6 +42,10 @@ type Response struct {
 	Res    *dns.Msg
 	Reason string
 	RType  ResponseType
+	// country code of the answer IP, if the response was blocked or flagged by GeoIP
+	Country string
+	// the query is not answered, e.g. if it was dropped by a response policy zone
+	Drop bool
 }
 
 // RequestProtocol represents the server protocol ENUM(
@@ -51,9 +57,12 @@ type RequestProtocol uint8
 // Request represents client's DNS request
 type Request struct {
 	ClientIP        net.IP
+	ClientMAC       net.HardwareAddr
 	RequestClientID string
 	Protocol        RequestProtocol
 	ClientNames     []string
+	Profile         string
+	Listener        string // local address of the DNS listener or path of the DoH request
 	Req             *dns.Msg
 	RequestTS       time.Time
 }
@@ -109,11 +109,14 @@ const (
 	// ResponseTypeSPECIAL is a ResponseType of type SPECIAL.
 	// the query was resolved by the special use domain name resolver
 	ResponseTypeSPECIAL
+	// ResponseTypeSAFESEARCH is a ResponseType of type SAFESEARCH.
+	// the query was rewritten to the safe search endpoint of a search engine
+	ResponseTypeSAFESEARCH
 )
 
 var ErrInvalidResponseType = fmt.Errorf("not a valid ResponseType, try [%s]", strings.Join(_ResponseTypeNames, ", "))
 
-const _ResponseTypeName = "RESOLVEDCACHEDBLOCKEDCONDITIONALCUSTOMDNSHOSTSFILEFILTEREDNOTFQDNSPECIAL"
+const _ResponseTypeName = "RESOLVEDCACHEDBLOCKEDCONDITIONALCUSTOMDNSHOSTSFILEFILTEREDNOTFQDNSPECIALSAFESEARCH"
 
 var _ResponseTypeNames = []string{
 	_ResponseTypeName[0:8],
@@ -125,6 +128,7 @@ var _ResponseTypeNames = []string{
 	_ResponseTypeName[50:58],
 	_ResponseTypeName[58:65],
 	_ResponseTypeName[65:72],
+	_ResponseTypeName[72:82],
 }
 
 // ResponseTypeNames returns a list of possible string values of ResponseType.
@@ -144,6 +148,7 @@ var _ResponseTypeMap = map[ResponseType]string{
 	ResponseTypeFILTERED:    _ResponseTypeName[50:58],
 	ResponseTypeNOTFQDN:     _ResponseTypeName[58:65],
 	ResponseTypeSPECIAL:     _ResponseTypeName[65:72],
+	ResponseTypeSAFESEARCH:  _ResponseTypeName[72:82],
 }
 
 // String implements the Stringer interface.
@@ -171,6 +176,7 @@ var _ResponseTypeValue = map[string]ResponseType{
 	_ResponseTypeName[50:58]: ResponseTypeFILTERED,
 	_ResponseTypeName[58:65]: ResponseTypeNOTFQDN,
 	_ResponseTypeName[65:72]: ResponseTypeSPECIAL,
+	_ResponseTypeName[72:82]: ResponseTypeSAFESEARCH,
 }
 
 // ParseResponseType attempts to convert a string to a ResponseType.
@@ -35,6 +35,7 @@ type logEntry struct {
 	Answer        string
 	ResponseCode  string
 	Hostname      string
+	Country       string
 }
 
 type DatabaseWriter struct {
@@ -177,6 +178,7 @@ func (d *DatabaseWriter) Write(entry *LogEntry) {
 		Answer:        entry.Answer,
 		ResponseCode:  entry.ResponseCode,
 		Hostname:      util.HostnameString(),
+		Country:       entry.Country,
 	}
 
 	d.lock.Lock()
@@ -116,6 +116,7 @@ func createQueryLogRow(logEntry *LogEntry) []string {
 		logEntry.ResponseType,
 		logEntry.QuestionType,
 		util.HostnameString(),
+		logEntry.Country,
 	}
 }
 
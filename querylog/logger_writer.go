@@ -41,6 +41,7 @@ func LogEntryFields(entry *LogEntry) logrus.Fields {
 		"answer":          entry.Answer,
 		"duration_ms":     entry.DurationMs,
 		"hostname":        util.HostnameString(),
+		"country":         entry.Country,
 	})
 }
 
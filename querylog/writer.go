@@ -15,6 +15,7 @@ type LogEntry struct {
 	QuestionType   string
 	QuestionName   string
 	Answer         string
+	Country        string
 }
 
 type Writer interface {
@@ -20,13 +20,14 @@ import (
 )
 
 const (
-	SyncChannelName   = "bGuard_sync"
-	CacheStorePrefix  = "bGuard:cache:"
-	chanCap           = 1000
-	cacheReason       = "EXTERNAL_CACHE"
-	defaultCacheTime  = 1 * time.Second
-	messageTypeCache  = 0
-	messageTypeEnable = 1
+	SyncChannelName       = "bGuard_sync"
+	CacheStorePrefix      = "bGuard:cache:"
+	chanCap               = 1000
+	cacheReason           = "EXTERNAL_CACHE"
+	defaultCacheTime      = 1 * time.Second
+	messageTypeCache      = 0
+	messageTypeEnable     = 1
+	messageTypeZoneUpdate = 2
 )
 
 // sendBuffer message
@@ -53,17 +54,28 @@ type EnabledMessage struct {
 	State    bool          `json:"s"`
 	Duration time.Duration `json:"d,omitempty"`
 	Groups   []string      `json:"g,omitempty"`
+	// the pause of a single client or profile is changed, if set
+	Client  string `json:"c,omitempty"`
+	Profile string `json:"p,omitempty"`
+}
+
+// ZoneUpdateMessage contains the changes of a dynamic update of an authoritative zone
+type ZoneUpdateMessage struct {
+	Zone string `json:"z"`
+	// IXFR difference sequence in presentation format
+	Differences []string `json:"d"`
 }
 
 // Client for redis communication
 type Client struct {
-	config         *config.Redis
-	client         *redis.Client
-	l              *logrus.Entry
-	id             []byte
-	sendBuffer     chan *bufferMessage
-	CacheChannel   chan *CacheMessage
-	EnabledChannel chan *EnabledMessage
+	config            *config.Redis
+	client            *redis.Client
+	l                 *logrus.Entry
+	id                []byte
+	sendBuffer        chan *bufferMessage
+	CacheChannel      chan *CacheMessage
+	EnabledChannel    chan *EnabledMessage
+	ZoneUpdateChannel chan *ZoneUpdateMessage
 }
 
 // New creates a new redis client
@@ -107,13 +119,14 @@ func New(ctx context.Context, cfg *config.Redis) (*Client, error) {
 		if err == nil {
 			// construct client
 			res := &Client{
-				config:         cfg,
-				client:         rdb,
-				l:              log.PrefixedLog("redis"),
-				id:             id,
-				sendBuffer:     make(chan *bufferMessage, chanCap),
-				CacheChannel:   make(chan *CacheMessage, chanCap),
-				EnabledChannel: make(chan *EnabledMessage, chanCap),
+				config:            cfg,
+				client:            rdb,
+				l:                 log.PrefixedLog("redis"),
+				id:                id,
+				sendBuffer:        make(chan *bufferMessage, chanCap),
+				CacheChannel:      make(chan *CacheMessage, chanCap),
+				EnabledChannel:    make(chan *EnabledMessage, chanCap),
+				ZoneUpdateChannel: make(chan *ZoneUpdateMessage, chanCap),
 			}
 
 			// start channel handling go routine
@@ -151,6 +164,22 @@ func (c *Client) PublishEnabled(ctx context.Context, state *EnabledMessage) {
 	}
 }
 
+// PublishZoneUpdate publishes the changes of an authoritative zone
+func (c *Client) PublishZoneUpdate(ctx context.Context, update *ZoneUpdateMessage) {
+	binUpdate, uErr := json.Marshal(update)
+	if uErr == nil {
+		binMsg, mErr := json.Marshal(redisMessage{
+			Type:    messageTypeZoneUpdate,
+			Message: binUpdate,
+			Client:  c.id,
+		})
+
+		if mErr == nil {
+			c.client.Publish(ctx, SyncChannelName, binMsg)
+		}
+	}
+}
+
 // GetRedisCache reads the redis cache and publish it to the channel
 func (c *Client) GetRedisCache(ctx context.Context) {
 	c.l.Debug("GetRedisCache")
@@ -268,6 +297,16 @@ func (c *Client) processReceivedMessage(ctx context.Context, msg *redis.Message)
 			}
 
 			util.CtxSend(ctx, c.EnabledChannel, &msg)
+		case messageTypeZoneUpdate:
+			var msg ZoneUpdateMessage
+
+			if err := json.Unmarshal(rm.Message, &msg); err != nil {
+				c.l.Error("Processing ZoneUpdateMessage error: ", err)
+
+				return
+			}
+
+			util.CtxSend(ctx, c.ZoneUpdateChannel, &msg)
 		default:
 			c.l.Warn("Unknown message type: ", rm.Type)
 		}
@@ -0,0 +1,360 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/Abiji-2020/bGuard/config"
+	"github.com/Abiji-2020/bGuard/model"
+	"github.com/Abiji-2020/bGuard/redis"
+	"github.com/Abiji-2020/bGuard/util"
+	"github.com/Abiji-2020/bGuard/zone"
+	"github.com/miekg/dns"
+	"github.com/sirupsen/logrus"
+)
+
+// maxExternalCNAMEs limits the CNAMEs leaving a zone which are followed for stub resolvers
+const maxExternalCNAMEs = 8
+
+// AuthoritativeResolver answers queries for the zones bGuard is authoritative for
+type AuthoritativeResolver struct {
+	configurable[*config.Authoritative]
+	NextResolver
+	typed
+
+	zones       *zone.Zones
+	secondaries map[string]*zone.Secondary
+	// allowed secondaries by zone origin
+	allowTransfer map[string][]string
+	// TSIG key names by zone origin
+	tsigKeys map[string]string
+	// TSIG key names allowed to send dynamic updates by zone origin
+	updateKeys map[string][]string
+	journals   map[string]*zone.Journal
+
+	redisClient *redis.Client
+}
+
+// NewAuthoritativeResolver creates a new resolver instance, loads the zones
+// and starts to refresh the secondary zones until ctx is done
+func NewAuthoritativeResolver(
+	ctx context.Context, cfg config.Authoritative, redisClient *redis.Client,
+) (*AuthoritativeResolver, error) {
+	r := &AuthoritativeResolver{
+		configurable: withConfig(&cfg),
+		typed:        withType("authoritative"),
+
+		zones:         zone.NewZones(),
+		secondaries:   make(map[string]*zone.Secondary),
+		allowTransfer: make(map[string][]string, len(cfg.Zones)),
+		tsigKeys:      make(map[string]string, len(cfg.Zones)),
+		updateKeys:    make(map[string][]string),
+		journals:      make(map[string]*zone.Journal),
+
+		redisClient: redisClient,
+	}
+
+	var errs []error
+
+	for name, zoneCfg := range cfg.Zones {
+		if err := r.addZone(name, zoneCfg); err != nil {
+			errs = append(errs, fmt.Errorf("zone '%s': %w", name, err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	for _, secondary := range r.secondaries {
+		go secondary.Run(ctx)
+	}
+
+	if r.redisClient != nil && len(r.updateKeys) > 0 {
+		go r.redisSubscriber(ctx)
+	}
+
+	return r, nil
+}
+
+func (r *AuthoritativeResolver) addZone(name string, cfg config.AuthoritativeZone) error {
+	origin := dns.CanonicalName(name)
+
+	var tsig *zone.TSIG
+
+	if cfg.TSIGKey != "" {
+		key, ok := r.cfg.TSIGKeys[cfg.TSIGKey]
+		if !ok {
+			return fmt.Errorf("unknown TSIG key '%s'", cfg.TSIGKey)
+		}
+
+		algorithm, err := key.TSIGAlgorithm()
+		if err != nil {
+			return err
+		}
+
+		tsig = &zone.TSIG{Name: dns.CanonicalName(cfg.TSIGKey), Algorithm: algorithm, Secret: key.Secret}
+		r.tsigKeys[origin] = tsig.Name
+	}
+
+	r.allowTransfer[origin] = cfg.AllowTransfer
+
+	if cfg.IsSecondary() {
+		if cfg.File != "" || cfg.Records != "" {
+			return errors.New("secondary zones can't have 'file' or 'records'")
+		}
+
+		if len(cfg.UpdateKeys) > 0 {
+			return errors.New("secondary zones can't have 'updateKeys'")
+		}
+
+		r.secondaries[origin] = zone.NewSecondary(origin, cfg.Primaries, tsig, r.zones)
+
+		return nil
+	}
+
+	z, err := loadZone(name, cfg)
+	if err != nil {
+		return err
+	}
+
+	if len(cfg.UpdateKeys) > 0 {
+		if err := r.enableUpdates(z, cfg); err != nil {
+			return err
+		}
+	}
+
+	r.zones.Add(z)
+
+	return nil
+}
+
+func loadZone(name string, cfg config.AuthoritativeZone) (*zone.Zone, error) {
+	switch {
+	case cfg.File != "" && cfg.Records != "":
+		return nil, errors.New("only one of 'file' and 'records' can be set")
+
+	case cfg.File != "":
+		f, err := os.Open(cfg.File)
+		if err != nil {
+			return nil, err
+		}
+
+		defer f.Close()
+
+		return zone.Parse(name, f, cfg.File)
+
+	case cfg.Records != "":
+		return zone.Parse(name, strings.NewReader(cfg.Records), "")
+	}
+
+	return nil, errors.New("one of 'file' and 'records' must be set")
+}
+
+// LogConfig implements `config.Configurable`.
+func (r *AuthoritativeResolver) LogConfig(logger *logrus.Entry) {
+	r.cfg.LogConfig(logger)
+
+	for _, z := range r.zones.All() {
+		logger.Infof("%s: serial = %d, records = %d", z.Origin(), z.Serial(), z.Len())
+	}
+
+	for origin, secondary := range r.secondaries {
+		if secondary.Zone() == nil {
+			logger.Infof("%s: waiting for zone transfer", origin)
+		}
+	}
+}
+
+// Resolve answers queries for names in the authoritative zones and passes all other queries to the next resolver
+func (r *AuthoritativeResolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
+	return r.resolve(ctx, request, 0)
+}
+
+func (r *AuthoritativeResolver) resolve(
+	ctx context.Context, request *model.Request, depth int,
+) (*model.Response, error) {
+	question := request.Req.Question[0]
+
+	ctx, logger := r.log(ctx)
+
+	response := new(dns.Msg)
+	response.SetReply(request.Req)
+
+	z := r.zones.Find(question.Name)
+	if z == nil {
+		if origin := r.unavailableSecondary(question.Name); origin != "" {
+			logger.WithField("zone", origin).Debug("secondary zone is not available")
+
+			response.Rcode = dns.RcodeServerFailure
+
+			return &model.Response{Res: response, RType: model.ResponseTypeCUSTOMDNS, Reason: "AUTHORITATIVE"}, nil
+		}
+
+		return r.next.Resolve(ctx, request)
+	}
+
+	if question.Qtype == dns.TypeAXFR || question.Qtype == dns.TypeIXFR {
+		// transfers are handled by the server, as they need the connection
+		response.Rcode = dns.RcodeRefused
+
+		return &model.Response{Res: response, RType: model.ResponseTypeCUSTOMDNS, Reason: "AUTHORITATIVE"}, nil
+	}
+
+	result := z.Lookup(question.Name, question.Qtype)
+
+	if result.Referral && request.Req.RecursionDesired {
+		// stub resolvers expect an answer, not a referral
+		logger.WithField("next_resolver", Name(r.next)).Debug("name is delegated, go to next resolver")
+
+		return r.next.Resolve(ctx, request)
+	}
+
+	response.Rcode = result.Rcode
+	response.Authoritative = result.Authoritative
+	response.Answer = result.Answer
+	response.Ns = result.Ns
+	response.Extra = result.Extra
+
+	if request.Req.RecursionDesired {
+		if err := r.followExternalCNAME(ctx, request, response, depth); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.WithFields(logrus.Fields{
+		"answer":      util.AnswerToString(response.Answer),
+		"return_code": dns.RcodeToString[response.Rcode],
+		"zone":        z.Origin(),
+	}).Debug("returning authoritative answer")
+
+	return &model.Response{Res: response, RType: model.ResponseTypeCUSTOMDNS, Reason: "AUTHORITATIVE"}, nil
+}
+
+// followExternalCNAME resolves the target of a CNAME pointing out of the zone, as stub resolvers don't
+func (r *AuthoritativeResolver) followExternalCNAME(
+	ctx context.Context, request *model.Request, response *dns.Msg, depth int,
+) error {
+	if response.Rcode != dns.RcodeSuccess || len(response.Answer) == 0 {
+		return nil
+	}
+
+	cname, ok := response.Answer[len(response.Answer)-1].(*dns.CNAME)
+	if !ok || request.Req.Question[0].Qtype == dns.TypeCNAME {
+		return nil
+	}
+
+	if depth >= maxExternalCNAMEs {
+		return fmt.Errorf("CNAME chain of %s is too long", request.Req.Question[0].Name)
+	}
+
+	target, err := r.resolve(ctx, subRequest(request, cname.Target, request.Req.Question[0].Qtype), depth+1)
+	if err != nil {
+		return err
+	}
+
+	response.Rcode = target.Res.Rcode
+	response.Answer = append(response.Answer, target.Res.Answer...)
+	response.Ns = nil
+	response.Extra = nil
+	// the data of the other zone is not authoritative
+	response.Authoritative = response.Authoritative && target.Res.Authoritative
+
+	return nil
+}
+
+// unavailableSecondary returns the origin of the secondary zone containing name,
+// if it wasn't transferred yet or is expired
+func (r *AuthoritativeResolver) unavailableSecondary(name string) string {
+	for origin, secondary := range r.secondaries {
+		if secondary.Zone() == nil && dns.IsSubDomain(origin, dns.CanonicalName(name)) {
+			return origin
+		}
+	}
+
+	return ""
+}
+
+// Transfer returns the records of a zone transfer (AXFR or IXFR) request.
+// tsigKey is the name of the verified TSIG key the request is signed with, if any.
+// If the transfer is not possible, the records are nil and the rcode tells why.
+func (r *AuthoritativeResolver) Transfer(request *model.Request, tsigKey string) (rrs []dns.RR, rcode int) {
+	question := request.Req.Question[0]
+
+	z := r.zones.Get(question.Name)
+	if z == nil {
+		return nil, dns.RcodeNotAuth
+	}
+
+	if !r.isTransferAllowed(z.Origin(), request.ClientIP, tsigKey) {
+		return nil, dns.RcodeRefused
+	}
+
+	if question.Qtype == dns.TypeAXFR {
+		return z.AXFR(), dns.RcodeSuccess
+	}
+
+	// the secondary's current SOA is in the authority section (RFC 1995 section 3)
+	for _, rr := range request.Req.Ns {
+		if soa, ok := rr.(*dns.SOA); ok {
+			return z.IXFR(soa.Serial), dns.RcodeSuccess
+		}
+	}
+
+	return nil, dns.RcodeFormatError
+}
+
+// Notify handles a NOTIFY message (RFC 1996) of a primary by refreshing the secondary zone.
+// tsigKey is the name of the verified TSIG key the message is signed with, if any.
+func (r *AuthoritativeResolver) Notify(request *model.Request, tsigKey string) (rcode int) {
+	origin := dns.CanonicalName(request.Req.Question[0].Name)
+
+	secondary, ok := r.secondaries[origin]
+	if !ok {
+		return dns.RcodeNotAuth
+	}
+
+	if key, ok := r.tsigKeys[origin]; ok {
+		if tsigKey != key {
+			return dns.RcodeRefused
+		}
+	} else if !secondary.IsPrimary(request.ClientIP) {
+		return dns.RcodeRefused
+	}
+
+	secondary.Notify()
+
+	return dns.RcodeSuccess
+}
+
+// isTransferAllowed checks the client IP against the allowed secondaries.
+// If the zone has a TSIG key, the request must be signed with it and the IPs are optional.
+func (r *AuthoritativeResolver) isTransferAllowed(origin string, clientIP net.IP, tsigKey string) bool {
+	allowTransfer := r.allowTransfer[origin]
+
+	if key, ok := r.tsigKeys[origin]; ok {
+		if tsigKey != key {
+			return false
+		}
+
+		if len(allowTransfer) == 0 {
+			return true
+		}
+	}
+
+	for _, allowed := range allowTransfer {
+		if ip := net.ParseIP(allowed); ip != nil && ip.Equal(clientIP) {
+			return true
+		}
+
+		if util.CidrContainsIP(allowed, clientIP) {
+			return true
+		}
+	}
+
+	return false
+}
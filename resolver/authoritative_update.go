@@ -0,0 +1,155 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/Abiji-2020/bGuard/config"
+	"github.com/Abiji-2020/bGuard/log"
+	"github.com/Abiji-2020/bGuard/model"
+	"github.com/Abiji-2020/bGuard/redis"
+	"github.com/Abiji-2020/bGuard/zone"
+	"github.com/miekg/dns"
+)
+
+// enableUpdates allows dynamic updates of the zone and replays its journal
+func (r *AuthoritativeResolver) enableUpdates(z *zone.Zone, cfg config.AuthoritativeZone) error {
+	keys := make([]string, len(cfg.UpdateKeys))
+
+	for i, key := range cfg.UpdateKeys {
+		if _, ok := r.cfg.TSIGKeys[key]; !ok {
+			return fmt.Errorf("unknown TSIG key '%s'", key)
+		}
+
+		keys[i] = dns.CanonicalName(key)
+	}
+
+	r.updateKeys[z.Origin()] = keys
+
+	path := cfg.JournalPath()
+	if path == "" {
+		return nil
+	}
+
+	journal := zone.NewJournal(path)
+	r.journals[z.Origin()] = journal
+
+	if err := journal.Replay(z); err != nil {
+		// the zone file was changed, its content is more recent than the journal
+		log.PrefixedLog(r.Type()).WithField("zone", z.Origin()).
+			Warnf("discarding journal '%s', it doesn't match the zone: %s", path, err)
+
+		return journal.Reset()
+	}
+
+	return nil
+}
+
+// Update applies a dynamic update (RFC 2136) to a zone.
+// tsigKey is the name of the verified TSIG key the request is signed with, if any.
+func (r *AuthoritativeResolver) Update(ctx context.Context, request *model.Request, tsigKey string) (rcode int) {
+	ctx, logger := r.log(ctx)
+
+	question := request.Req.Question
+	if len(question) != 1 || question[0].Qtype != dns.TypeSOA {
+		return dns.RcodeFormatError
+	}
+
+	origin := dns.CanonicalName(question[0].Name)
+
+	z := r.zones.Get(origin)
+	if z == nil {
+		return dns.RcodeNotAuth
+	}
+
+	if tsigKey == "" || !slices.Contains(r.updateKeys[origin], tsigKey) {
+		return dns.RcodeRefused
+	}
+
+	var diffs []dns.RR
+
+	rcode, err := z.Update(request.Req, func(d []dns.RR) error {
+		diffs = d
+
+		if journal, ok := r.journals[origin]; ok {
+			return journal.Append(d)
+		}
+
+		return nil
+	})
+	if err != nil {
+		logger.Error("can't apply dynamic update: ", err)
+
+		return rcode
+	}
+
+	if rcode != dns.RcodeSuccess || diffs == nil {
+		return rcode
+	}
+
+	logger.WithField("zone", origin).Infof("applied dynamic update, new serial %d", z.Serial())
+
+	if r.redisClient != nil {
+		update := &redis.ZoneUpdateMessage{Zone: origin, Differences: make([]string, len(diffs))}
+
+		for i, rr := range diffs {
+			update.Differences[i] = rr.String()
+		}
+
+		r.redisClient.PublishZoneUpdate(ctx, update)
+	}
+
+	return rcode
+}
+
+// redisSubscriber applies the dynamic updates received by other instances
+func (r *AuthoritativeResolver) redisSubscriber(ctx context.Context) {
+	ctx, logger := r.log(ctx)
+
+	for {
+		select {
+		case update := <-r.redisClient.ZoneUpdateChannel:
+			if update != nil {
+				logger.Debug("Received zone update from redis: ", update.Zone)
+
+				if err := r.applyRemoteUpdate(update); err != nil {
+					logger.WithField("zone", update.Zone).Warn("can't apply zone update from redis: ", err)
+				}
+			}
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *AuthoritativeResolver) applyRemoteUpdate(update *redis.ZoneUpdateMessage) error {
+	origin := dns.CanonicalName(update.Zone)
+
+	z := r.zones.Get(origin)
+	if z == nil || len(r.updateKeys[origin]) == 0 {
+		return fmt.Errorf("zone '%s' doesn't allow dynamic updates", update.Zone)
+	}
+
+	diffs := make([]dns.RR, len(update.Differences))
+
+	for i, s := range update.Differences {
+		rr, err := dns.NewRR(s)
+		if err != nil {
+			return err
+		}
+
+		diffs[i] = rr
+	}
+
+	if err := z.ApplyDifferences(diffs); err != nil {
+		return err
+	}
+
+	if journal, ok := r.journals[origin]; ok {
+		return journal.Append(diffs)
+	}
+
+	return nil
+}
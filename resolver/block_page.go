@@ -0,0 +1,185 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Abiji-2020/bGuard/api"
+	"github.com/Abiji-2020/bGuard/cache/expirationcache"
+	"github.com/Abiji-2020/bGuard/config"
+	"github.com/Abiji-2020/bGuard/log"
+	"github.com/Abiji-2020/bGuard/model"
+	"github.com/Abiji-2020/bGuard/util"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// maximum count of blocked queries kept for the block page
+	blockPageMaxQueries = 10_000
+	// maximum count of unblock requests, the oldest request is dropped
+	maxUnblockRequests = 1_000
+	// MaxUnblockCommentLength is the maximum length of the comment of an unblock request
+	MaxUnblockCommentLength = 500
+	// minimum time a blocked query is kept for the block page
+	minBlockPageRetention = time.Minute
+)
+
+// BlockedQuery is a blocked query of a client, shown by the block page
+type BlockedQuery struct {
+	Domain string
+	// name or IP address of the client
+	Client string
+	// matching denylist groups, empty if blocked by an allowlist only group
+	Groups []string
+	// the reason of the response, e.g. BLOCKED (group)
+	Reason string
+}
+
+// blockPage keeps the recently blocked queries of the clients and their unblock requests
+type blockPage struct {
+	cfg       config.BlockPage
+	retention time.Duration
+	// blocked queries by client IP and domain
+	queries expirationcache.ExpiringCache[BlockedQuery]
+
+	lock     sync.Mutex
+	requests []api.UnblockRequest
+}
+
+func newBlockPage(ctx context.Context, cfg config.Blocking) *blockPage {
+	if !cfg.BlockPage.IsEnabled() {
+		return nil
+	}
+
+	return &blockPage{
+		cfg:       cfg.BlockPage,
+		retention: max(cfg.BlockTTL.ToDuration(), minBlockPageRetention),
+		queries: expirationcache.NewCache[BlockedQuery](ctx, expirationcache.Options{
+			CleanupInterval: defaultBlockingCleanUpInterval,
+			MaxSize:         blockPageMaxQueries,
+		}),
+	}
+}
+
+func blockedQueryKey(clientIP net.IP, domain string) string {
+	return fmt.Sprintf("%s %s", clientIP, strings.ToLower(strings.TrimSuffix(domain, ".")))
+}
+
+// recordBlockedQuery keeps the blocked query of the request for the block page
+func (p *blockPage) recordBlockedQuery(request *model.Request, groups []string, reason string) {
+	domain := util.ExtractDomain(request.Req.Question[0])
+
+	client := request.ClientIP.String()
+	if len(request.ClientNames) != 0 {
+		client = request.ClientNames[0]
+	}
+
+	p.queries.Put(blockedQueryKey(request.ClientIP, domain), &BlockedQuery{
+		Domain: domain,
+		Client: client,
+		Groups: groups,
+		Reason: reason,
+	}, p.retention)
+}
+
+// BlockedQuery returns the recently blocked query of a client for a domain,
+// false if the block page is disabled or the domain was not blocked for the client
+func (r *BlockingResolver) BlockedQuery(clientIP net.IP, domain string) (BlockedQuery, bool) {
+	if r.blockPage == nil {
+		return BlockedQuery{}, false
+	}
+
+	query, _ := r.blockPage.queries.Get(blockedQueryKey(clientIP, domain))
+	if query == nil {
+		return BlockedQuery{}, false
+	}
+
+	return *query, true
+}
+
+// UnblockRequestsEnabled returns true if clients can request the unblocking of a domain from the block page
+func (r *BlockingResolver) UnblockRequestsEnabled() bool {
+	return r.blockPage != nil && r.blockPage.cfg.UnblockRequests
+}
+
+// RequestUnblock records the request of a client to unblock a domain, which was recently blocked for it
+func (r *BlockingResolver) RequestUnblock(ctx context.Context, clientIP net.IP, domain, comment string) error {
+	if !r.UnblockRequestsEnabled() {
+		return errors.New("unblock requests are disabled")
+	}
+
+	query, ok := r.BlockedQuery(clientIP, domain)
+	if !ok {
+		return fmt.Errorf("domain '%s' was not blocked for the client", domain)
+	}
+
+	comment = strings.TrimSpace(comment)
+	if runes := []rune(comment); len(runes) > MaxUnblockCommentLength {
+		comment = string(runes[:MaxUnblockCommentLength])
+	}
+
+	p := r.blockPage
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	// a repeated request replaces the pending one
+	p.requests = slices.DeleteFunc(p.requests, func(request api.UnblockRequest) bool {
+		return request.Domain == query.Domain && request.Client == query.Client
+	})
+
+	if len(p.requests) >= maxUnblockRequests {
+		p.requests = slices.Delete(p.requests, 0, len(p.requests)-maxUnblockRequests+1)
+	}
+
+	p.requests = append(p.requests, api.UnblockRequest{
+		ID:          uuid.New().String(),
+		Domain:      query.Domain,
+		Client:      query.Client,
+		Reason:      query.Reason,
+		Comment:     comment,
+		RequestedAt: time.Now(),
+	})
+
+	_, logger := r.log(ctx)
+	logger.Infof("unblock request of client '%s' for domain '%s'",
+		log.EscapeInput(query.Client), log.EscapeInput(query.Domain))
+
+	return nil
+}
+
+// UnblockRequests returns the unblock requests, the oldest first
+func (r *BlockingResolver) UnblockRequests() []api.UnblockRequest {
+	if r.blockPage == nil {
+		return nil
+	}
+
+	r.blockPage.lock.Lock()
+	defer r.blockPage.lock.Unlock()
+
+	return slices.Clone(r.blockPage.requests)
+}
+
+// DismissUnblockRequest removes a reviewed unblock request
+func (r *BlockingResolver) DismissUnblockRequest(id string) error {
+	if r.blockPage != nil {
+		p := r.blockPage
+		p.lock.Lock()
+		defer p.lock.Unlock()
+
+		i := slices.IndexFunc(p.requests, func(request api.UnblockRequest) bool { return request.ID == id })
+		if i >= 0 {
+			p.requests = slices.Delete(p.requests, i, i+1)
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unblock request '%s' is unknown", id)
+}
@@ -0,0 +1,102 @@
+package resolver
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/Abiji-2020/bGuard/config"
+	"github.com/Abiji-2020/bGuard/geoip"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	geoIPActionBlock = "block"
+	geoIPActionFlag  = "flag"
+)
+
+// geoIPFilter blocks or flags answers by the country of their IPs
+type geoIPFilter struct {
+	db *geoip.Database
+	// upper case country codes by group name
+	block map[string][]string
+	flag  map[string][]string
+}
+
+// newGeoIPFilter returns nil if GeoIP is disabled
+func newGeoIPFilter(cfg config.GeoIP) (*geoIPFilter, error) {
+	if !cfg.IsEnabled() {
+		return nil, nil
+	}
+
+	db, err := geoip.Open(cfg.Database)
+	if err != nil {
+		return nil, fmt.Errorf("can't open GeoIP database: %w", err)
+	}
+
+	upper := func(countries map[string][]string) map[string][]string {
+		res := make(map[string][]string, len(countries))
+
+		for group, codes := range countries {
+			for _, code := range codes {
+				res[group] = append(res[group], strings.ToUpper(strings.TrimSpace(code)))
+			}
+		}
+
+		return res
+	}
+
+	return &geoIPFilter{
+		db:    db,
+		block: upper(cfg.Block),
+		flag:  upper(cfg.Flag),
+	}, nil
+}
+
+// match returns the country of the first answer IP, which is blocked for one of the groups, and the matching groups.
+// Without blocked IP, it returns the country of the first flagged IP.
+func (f *geoIPFilter) match(groupsToCheck []string, answer []dns.RR) (country, action string, groups []string) {
+	for _, rr := range answer {
+		var ip net.IP
+
+		switch v := rr.(type) {
+		case *dns.A:
+			ip = v.A
+		case *dns.AAAA:
+			ip = v.AAAA
+		default:
+			continue
+		}
+
+		code, ok := f.db.Country(ip)
+		if !ok {
+			continue
+		}
+
+		if matched := groupsWithCountry(f.block, groupsToCheck, code); len(matched) != 0 {
+			return code, geoIPActionBlock, matched
+		}
+
+		if action == "" {
+			if matched := groupsWithCountry(f.flag, groupsToCheck, code); len(matched) != 0 {
+				country, action, groups = code, geoIPActionFlag, matched
+			}
+		}
+	}
+
+	return country, action, groups
+}
+
+func groupsWithCountry(countries map[string][]string, groupsToCheck []string, country string) []string {
+	var groups []string
+
+	for _, group := range groupsToCheck {
+		if slices.Contains(countries[group], country) {
+			groups = append(groups, group)
+		}
+	}
+
+	return groups
+}
@@ -0,0 +1,243 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Abiji-2020/bGuard/api"
+	"github.com/Abiji-2020/bGuard/log"
+	"github.com/Abiji-2020/bGuard/model"
+	"github.com/Abiji-2020/bGuard/redis"
+
+	"golang.org/x/exp/maps"
+)
+
+// blockingPause pauses blocking for a single client or profile
+type blockingPause struct {
+	client  string
+	profile string
+	// all groups if empty
+	groups []string
+	// nil for a profile
+	clients *clientMatcher[bool]
+	// zero if paused until resumed
+	end   time.Time
+	timer *time.Timer
+}
+
+// pauseKey returns the key of the pause of a client or profile
+func pauseKey(client, profile string) (string, error) {
+	switch {
+	case client != "" && profile != "":
+		return "", errors.New("either a client or a profile can be paused")
+	case client != "":
+		return "client:" + strings.ToLower(client), nil
+	case profile != "":
+		return "profile:" + profile, nil
+	default:
+		return "", errors.New("no client or profile to pause")
+	}
+}
+
+// appliesTo returns true if the pause applies to the client of the request
+func (p *blockingPause) appliesTo(request *model.Request) bool {
+	if p.clients == nil {
+		return p.profile == request.Profile
+	}
+
+	_, ok := p.clients.match(request)
+
+	return ok
+}
+
+// PauseBlocking deactivates the blocking of a single client or profile for a particular duration
+// (or until resumed if 0).
+func (r *BlockingResolver) PauseBlocking(ctx context.Context, client, profile string, duration time.Duration,
+	pauseGroups []string,
+) error {
+	err := r.internalPauseBlocking(ctx, client, profile, duration, pauseGroups)
+	if err == nil && r.redisClient != nil {
+		r.redisClient.PublishEnabled(ctx, &redis.EnabledMessage{
+			State:    false,
+			Duration: duration,
+			Groups:   pauseGroups,
+			Client:   client,
+			Profile:  profile,
+		})
+	}
+
+	return err
+}
+
+func (r *BlockingResolver) internalPauseBlocking(ctx context.Context, client, profile string, duration time.Duration,
+	pauseGroups []string,
+) error {
+	key, err := pauseKey(client, profile)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := r.profiles[profile]; profile != "" && !ok {
+		return fmt.Errorf("profile '%s' is unknown", profile)
+	}
+
+	allBlockingGroups := r.retrieveAllBlockingGroups()
+
+	for _, g := range pauseGroups {
+		if !slices.Contains(allBlockingGroups, g) {
+			return fmt.Errorf("group '%s' is unknown", g)
+		}
+	}
+
+	pause := &blockingPause{
+		client:  client,
+		profile: profile,
+		groups:  pauseGroups,
+	}
+
+	if client != "" {
+		pause.clients = newClientMatcher[bool]()
+		pause.clients.add(strings.ToLower(client), true)
+	}
+
+	s := r.status
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if existing, ok := s.pauses[key]; ok && existing.timer != nil {
+		existing.timer.Stop()
+	}
+
+	groupNames := "all"
+	if len(pauseGroups) != 0 {
+		groupNames = strings.Join(pauseGroups, "; ")
+	}
+
+	if duration == 0 {
+		log.Log().Infof("pause blocking of %s for group(s) '%s'", log.EscapeInput(key), log.EscapeInput(groupNames))
+	} else {
+		log.Log().Infof("pause blocking of %s for %s for group(s) '%s'", log.EscapeInput(key), duration,
+			log.EscapeInput(groupNames))
+
+		pause.end = time.Now().Add(duration)
+		pause.timer = time.AfterFunc(duration, func() {
+			r.expirePause(ctx, key, pause)
+		})
+	}
+
+	s.pauses[key] = pause
+
+	return nil
+}
+
+// expirePause ends the pause when its timer fired. A stopped timer may still run its function,
+// so the pause is only removed if it wasn't resumed or replaced by a new pause in the meantime.
+func (r *BlockingResolver) expirePause(ctx context.Context, key string, pause *blockingPause) {
+	s := r.status
+	s.lock.Lock()
+
+	current := s.pauses[key]
+	if current == pause {
+		delete(s.pauses, key)
+	}
+
+	s.lock.Unlock()
+
+	if current != pause {
+		return
+	}
+
+	log.Log().Infof("blocking of %s resumed", log.EscapeInput(key))
+
+	r.publishResumed(ctx, pause.client, pause.profile)
+}
+
+// ResumeBlocking ends the blocking pause of a client or profile
+func (r *BlockingResolver) ResumeBlocking(ctx context.Context, client, profile string) error {
+	err := r.internalResumeBlocking(client, profile)
+	if err == nil {
+		r.publishResumed(ctx, client, profile)
+	}
+
+	return err
+}
+
+func (r *BlockingResolver) publishResumed(ctx context.Context, client, profile string) {
+	if r.redisClient != nil {
+		r.redisClient.PublishEnabled(ctx, &redis.EnabledMessage{
+			State:   true,
+			Client:  client,
+			Profile: profile,
+		})
+	}
+}
+
+func (r *BlockingResolver) internalResumeBlocking(client, profile string) error {
+	key, err := pauseKey(client, profile)
+	if err != nil {
+		return err
+	}
+
+	s := r.status
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if pause, ok := s.pauses[key]; ok {
+		if pause.timer != nil {
+			pause.timer.Stop()
+		}
+
+		delete(s.pauses, key)
+	}
+
+	return nil
+}
+
+// withoutPausedGroups removes the groups paused for the client of the request, the status lock must be held
+func (r *BlockingResolver) withoutPausedGroups(request *model.Request, groups []string) []string {
+	for _, pause := range r.status.pauses {
+		if !pause.appliesTo(request) {
+			continue
+		}
+
+		if len(pause.groups) == 0 {
+			return nil
+		}
+
+		groups = slices.DeleteFunc(slices.Clone(groups), func(group string) bool {
+			return slices.Contains(pause.groups, group)
+		})
+	}
+
+	return groups
+}
+
+// blockingPauses returns the current pauses sorted by client and profile, the status lock must be held
+func (r *BlockingResolver) blockingPauses() []api.BlockingPause {
+	keys := maps.Keys(r.status.pauses)
+	slices.Sort(keys)
+
+	result := make([]api.BlockingPause, 0, len(keys))
+
+	for _, key := range keys {
+		pause := r.status.pauses[key]
+
+		var autoEnableDuration time.Duration
+		if !pause.end.IsZero() {
+			autoEnableDuration = time.Until(pause.end)
+		}
+
+		result = append(result, api.BlockingPause{
+			Client:          pause.client,
+			Profile:         pause.profile,
+			Groups:          pause.groups,
+			AutoEnableInSec: int(autoEnableDuration.Seconds()),
+		})
+	}
+
+	return result
+}
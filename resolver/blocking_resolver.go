@@ -17,12 +17,16 @@ import (
 	"github.com/hashicorp/go-multierror"
 
 	"github.com/Abiji-2020/bGuard/api"
+	"github.com/Abiji-2020/bGuard/cache/stringcache"
 	"github.com/Abiji-2020/bGuard/config"
 	"github.com/Abiji-2020/bGuard/evt"
+	"github.com/Abiji-2020/bGuard/geoip"
 	"github.com/Abiji-2020/bGuard/lists"
+	"github.com/Abiji-2020/bGuard/lists/parsers"
 	"github.com/Abiji-2020/bGuard/log"
 	"github.com/Abiji-2020/bGuard/model"
 	"github.com/Abiji-2020/bGuard/redis"
+	"github.com/Abiji-2020/bGuard/trie"
 	"github.com/Abiji-2020/bGuard/util"
 
 	"github.com/miekg/dns"
@@ -32,12 +36,16 @@ import (
 const defaultBlockingCleanUpInterval = 5 * time.Second
 
 func createBlockHandler(cfg config.Blocking) (blockHandler, error) {
-	cfgBlockType := cfg.BlockType
+	cfgBlockType := strings.TrimSpace(cfg.BlockType)
 
 	if strings.EqualFold(cfgBlockType, "NXDOMAIN") {
 		return nxDomainBlockHandler{}, nil
 	}
 
+	if strings.EqualFold(cfgBlockType, "NODATA") {
+		return noDataBlockHandler{}, nil
+	}
+
 	blockTime := cfg.BlockTTL.SecondsU32()
 
 	if strings.EqualFold(cfgBlockType, "ZEROIP") {
@@ -64,9 +72,87 @@ func createBlockHandler(cfg config.Blocking) (blockHandler, error) {
 		}, nil
 	}
 
+	if _, ok := dns.IsDomainName(cfgBlockType); ok && strings.Contains(strings.Trim(cfgBlockType, "."), ".") {
+		return cnameBlockHandler{
+			target:       strings.ToLower(dns.Fqdn(cfgBlockType)),
+			BlockTimeSec: blockTime,
+		}, nil
+	}
+
 	return nil,
-		fmt.Errorf("unknown blockType '%s', please use one of: ZeroIP, NxDomain or specify destination IP address(es)",
-			cfgBlockType)
+		fmt.Errorf("unknown blockType '%s', please use one of: ZeroIP, NxDomain, NoData, "+
+			"specify destination IP address(es) or a host name", cfgBlockType)
+}
+
+// createBlockHandlerWithPage creates the block handler, which answers A/AAAA queries with the block page if enabled
+func createBlockHandlerWithPage(cfg config.Blocking) (blockHandler, error) {
+	handler, err := createBlockHandler(cfg)
+	if err != nil || !cfg.BlockPage.IsEnabled() {
+		return handler, err
+	}
+
+	return ipBlockHandler{
+		destinations:    cfg.BlockPage.IPs,
+		BlockTimeSec:    cfg.BlockTTL.SecondsU32(),
+		fallbackHandler: handler,
+	}, nil
+}
+
+// createGroupBlockHandlers creates the block handlers of the denylist groups with their own block type or TTL
+func createGroupBlockHandlers(cfg config.Blocking) (map[string]blockHandler, error) {
+	handlers := make(map[string]blockHandler, len(cfg.Groups))
+
+	for name, group := range cfg.Groups {
+		if group.BlockType == "" && group.BlockTTL == nil {
+			continue
+		}
+
+		groupCfg := cfg
+
+		if group.BlockType != "" {
+			// an explicit block type replaces the block page
+			groupCfg.BlockType = group.BlockType
+			groupCfg.BlockPage = config.BlockPage{}
+		}
+
+		if group.BlockTTL != nil {
+			groupCfg.BlockTTL = *group.BlockTTL
+		}
+
+		handler, err := createBlockHandlerWithPage(groupCfg)
+		if err != nil {
+			return nil, fmt.Errorf("group '%s': %w", name, err)
+		}
+
+		handlers[name] = handler
+	}
+
+	return handlers, nil
+}
+
+// createProfileBlockHandlers creates the block handlers of the profiles with their own block type
+func createProfileBlockHandlers(cfg config.Blocking, profiles config.Profiles) (map[string]blockHandler, error) {
+	handlers := make(map[string]blockHandler)
+
+	for name, profile := range profiles {
+		if profile.BlockType == "" {
+			continue
+		}
+
+		// like for groups, an explicit block type replaces the block page
+		profileCfg := cfg
+		profileCfg.BlockType = profile.BlockType
+		profileCfg.BlockPage = config.BlockPage{}
+
+		handler, err := createBlockHandlerWithPage(profileCfg)
+		if err != nil {
+			return nil, fmt.Errorf("profile '%s': %w", name, err)
+		}
+
+		handlers[name] = handler
+	}
+
+	return handlers, nil
 }
 
 type status struct {
@@ -76,7 +162,9 @@ type status struct {
 	disabledGroups []string
 	enableTimer    *time.Timer
 	disableEnd     time.Time
-	lock           sync.RWMutex
+	// pauses of single clients or profiles by client or profile
+	pauses map[string]*blockingPause
+	lock   sync.RWMutex
 }
 
 // BlockingResolver checks request's question (domain name) against allow/denylists
@@ -85,21 +173,48 @@ type BlockingResolver struct {
 	NextResolver
 	typed
 
-	denylistMatcher     *lists.ListCache
-	allowlistMatcher    *lists.ListCache
-	blockHandler        blockHandler
-	allowlistOnlyGroups map[string]bool
-	status              *status
-	clientGroupsBlock   map[string][]string
-	redisClient         *redis.Client
-	fqdnIPCache         expirationcache.ExpiringCache[[]net.IP]
-}
-
-func clientGroupsBlock(cfg config.Blocking) map[string][]string {
+	denylistMatcher  *lists.ListCache
+	allowlistMatcher *lists.ListCache
+	blockHandler     blockHandler
+	profiles         config.Profiles
+	// block handlers of the profiles and denylist groups with their own block type or TTL
+	profileBlockHandlers map[string]blockHandler
+	groupBlockHandlers   map[string]blockHandler
+	allowlistOnlyGroups  map[string]bool
+	status               *status
+	clientGroupsBlock    map[string][]string
+	clientGroupsByIP     *trie.IPTrie[[]string]
+	schedules            []blockingSchedule
+	redisClient          *redis.Client
+	fqdnIPCache          expirationcache.ExpiringCache[[]net.IP]
+	// nil if the block page is disabled
+	blockPage *blockPage
+	// nil if GeoIP is disabled
+	geoIP *geoIPFilter
+}
+
+// clientGroupsBlock returns the groups by client name, MAC address or FQDN,
+// and the groups of IP addresses, subnets and ranges by prefix
+func clientGroupsBlock(cfg config.Blocking) (map[string][]string, *trie.IPTrie[[]string]) {
 	cgb := make(map[string][]string, len(cfg.ClientGroupsBlock))
+	byIP := trie.NewIPTrie[[]string]()
 
 	for identifier, cfgGroups := range cfg.ClientGroupsBlock {
 		for _, ipart := range strings.Split(strings.ToLower(identifier), ",") {
+			if prefixes, ok := util.ParseIPPrefixes(ipart); ok {
+				for _, prefix := range prefixes {
+					existingGroups, _ := byIP.Get(prefix)
+					byIP.Insert(prefix, append(slices.Clip(existingGroups), cfgGroups...))
+				}
+
+				continue
+			}
+
+			if mac, err := net.ParseMAC(ipart); err == nil {
+				// MAC addresses have different notations
+				ipart = mac.String()
+			}
+
 			existingGroups, found := cgb[ipart]
 			if found {
 				cgb[ipart] = append(existingGroups, cfgGroups...)
@@ -109,16 +224,51 @@ func clientGroupsBlock(cfg config.Blocking) map[string][]string {
 		}
 	}
 
-	return cgb
+	return cgb, byIP
+}
+
+// openASNLookup opens the ASN database, the lookup is nil if no database is configured
+func openASNLookup(cfg config.Blocking) (stringcache.ASNLookup, error) {
+	if cfg.ASNDatabase == "" {
+		return nil, nil
+	}
+
+	db, err := geoip.Open(cfg.ASNDatabase)
+	if err != nil {
+		return nil, fmt.Errorf("can't open ASN database: %w", err)
+	}
+
+	return db.ASN, nil
 }
 
 // NewBlockingResolver returns a new configured instance of the resolver
 func NewBlockingResolver(ctx context.Context,
 	cfg config.Blocking,
+	profiles config.Profiles,
 	redis *redis.Client,
 	bootstrap *Bootstrap,
 ) (r *BlockingResolver, err error) {
-	blockHandler, err := createBlockHandler(cfg)
+	blockHandler, err := createBlockHandlerWithPage(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	groupBlockHandlers, err := createGroupBlockHandlers(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	profileBlockHandlers, err := createProfileBlockHandlers(cfg, profiles)
+	if err != nil {
+		return nil, err
+	}
+
+	asnLookup, err := openASNLookup(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	geoIP, err := newGeoIPFilter(cfg.GeoIP)
 	if err != nil {
 		return nil, err
 	}
@@ -126,9 +276,9 @@ func NewBlockingResolver(ctx context.Context,
 	downloader := lists.NewDownloader(cfg.Loading.Downloads, bootstrap.NewHTTPTransport())
 
 	denylistMatcher, blErr := lists.NewListCache(ctx, lists.ListCacheTypeDenylist,
-		cfg.Loading, cfg.Denylists, downloader)
+		cfg.Loading, cfg.Denylists, downloader, asnLookup)
 	allowlistMatcher, wlErr := lists.NewListCache(ctx, lists.ListCacheTypeAllowlist,
-		cfg.Loading, cfg.Allowlists, downloader)
+		cfg.Loading, cfg.Allowlists, downloader, asnLookup)
 	allowlistOnlyGroups := determineAllowlistOnlyGroups(&cfg)
 
 	err = multierror.Append(err, blErr, wlErr).ErrorOrNil()
@@ -140,18 +290,26 @@ func NewBlockingResolver(ctx context.Context,
 		configurable: withConfig(&cfg),
 		typed:        withType("blocking"),
 
-		blockHandler:        blockHandler,
-		denylistMatcher:     denylistMatcher,
-		allowlistMatcher:    allowlistMatcher,
-		allowlistOnlyGroups: allowlistOnlyGroups,
+		blockHandler:         blockHandler,
+		profiles:             profiles,
+		profileBlockHandlers: profileBlockHandlers,
+		groupBlockHandlers:   groupBlockHandlers,
+		denylistMatcher:      denylistMatcher,
+		allowlistMatcher:     allowlistMatcher,
+		allowlistOnlyGroups:  allowlistOnlyGroups,
 		status: &status{
 			enabled:     true,
 			enableTimer: time.NewTimer(0),
+			pauses:      make(map[string]*blockingPause),
 		},
-		clientGroupsBlock: clientGroupsBlock(cfg),
-		redisClient:       redis,
+		redisClient: redis,
+		schedules:   newBlockingSchedules(cfg.Schedules),
+		blockPage:   newBlockPage(ctx, cfg),
+		geoIP:       geoIP,
 	}
 
+	res.clientGroupsBlock, res.clientGroupsByIP = clientGroupsBlock(cfg)
+
 	res.fqdnIPCache = expirationcache.NewCacheWithOnExpired[[]net.IP](ctx, expirationcache.Options{
 		CleanupInterval: defaultBlockingCleanUpInterval,
 	}, func(ctx context.Context, key string) (val *[]net.IP, ttl time.Duration) {
@@ -164,6 +322,10 @@ func NewBlockingResolver(ctx context.Context,
 
 	err = evt.Bus().SubscribeOnce(evt.ApplicationStarted, func(_ ...string) {
 		go res.initFQDNIPCache(ctx)
+
+		if len(res.schedules) != 0 {
+			go res.watchSchedules(ctx)
+		}
 	})
 	if err != nil {
 		return nil, err
@@ -181,6 +343,12 @@ func (r *BlockingResolver) redisSubscriber(ctx context.Context) {
 			if em != nil {
 				logger.Debug("Received state from redis: ", em)
 
+				if em.Client != "" || em.Profile != "" {
+					r.handlePauseMessage(ctx, em)
+
+					continue
+				}
+
 				if em.State {
 					r.internalEnableBlocking()
 				} else {
@@ -197,6 +365,21 @@ func (r *BlockingResolver) redisSubscriber(ctx context.Context) {
 	}
 }
 
+func (r *BlockingResolver) handlePauseMessage(ctx context.Context, em *redis.EnabledMessage) {
+	var err error
+
+	if em.State {
+		err = r.internalResumeBlocking(em.Client, em.Profile)
+	} else {
+		err = r.internalPauseBlocking(ctx, em.Client, em.Profile, em.Duration, em.Groups)
+	}
+
+	if err != nil {
+		_, logger := r.log(ctx)
+		logger.Warn("Blocking pause couldn't be changed:", err)
+	}
+}
+
 // RefreshLists triggers the refresh of all allow/denylists in the cache
 func (r *BlockingResolver) RefreshLists() error {
 	var err *multierror.Error
@@ -308,6 +491,8 @@ func (r *BlockingResolver) BlockingStatus() api.BlockingStatus {
 		Enabled:         r.status.enabled,
 		DisabledGroups:  r.status.disabledGroups,
 		AutoEnableInSec: int(autoEnableDuration.Seconds()),
+		ActiveSchedules: r.activeSchedules(),
+		Pauses:          r.blockingPauses(),
 	}
 }
 
@@ -326,14 +511,51 @@ func determineAllowlistOnlyGroups(cfg *config.Blocking) (result map[string]bool)
 	return
 }
 
+// blockHandlerFor returns the block handler of the first matched group with its own block type or TTL,
+// otherwise the one of the client's profile or the default one
+func (r *BlockingResolver) blockHandlerFor(request *model.Request, groups []string) blockHandler {
+	for _, group := range groups {
+		if handler, ok := r.groupBlockHandlers[group]; ok {
+			return handler
+		}
+	}
+
+	if handler, ok := r.profileBlockHandlers[request.Profile]; ok {
+		return handler
+	}
+
+	return r.blockHandler
+}
+
 // sets answer and/or return code for DNS response, if request should be blocked
-func (r *BlockingResolver) handleBlocked(logger *logrus.Entry,
-	request *model.Request, question dns.Question, reason string,
+func (r *BlockingResolver) handleBlocked(ctx context.Context, logger *logrus.Entry,
+	request *model.Request, question dns.Question, groups []string, reason string,
+) (*model.Response, error) {
+	return r.respondBlocked(ctx, logger, request, question, r.blockHandlerFor(request, groups), groups, reason)
+}
+
+// respondBlocked answers the request with the block handler
+func (r *BlockingResolver) respondBlocked(ctx context.Context, logger *logrus.Entry,
+	request *model.Request, question dns.Question, handler blockHandler, groups []string, reason string,
 ) (*model.Response, error) {
 	response := new(dns.Msg)
 	response.SetReply(request.Req)
 
-	r.blockHandler.handleBlock(question, response)
+	handler.handleBlock(question, response)
+
+	if target, ok := cnameTarget(handler, question); ok && question.Qtype != dns.TypeCNAME {
+		// the answer contains the records of the CNAME target
+		targetResp, err := r.next.Resolve(ctx, subRequest(request, target, question.Qtype))
+		if err != nil {
+			return nil, err
+		}
+
+		response.Answer = append(response.Answer, targetResp.Res.Answer...)
+	}
+
+	if r.blockPage != nil {
+		r.blockPage.recordBlockedQuery(request, groups, reason)
+	}
 
 	logger.Debugf("blocking request '%s'", reason)
 
@@ -370,8 +592,9 @@ func (r *BlockingResolver) handleDenylist(ctx context.Context, groupsToCheck []s
 	for _, question := range request.Req.Question {
 		domain := util.ExtractDomain(question)
 		logger := logger.WithField("domain", domain)
+		query := filterQuery(request, question)
 
-		if groups := r.matches(groupsToCheck, r.allowlistMatcher, domain); len(groups) > 0 {
+		if groups := r.matches(groupsToCheck, r.allowlistMatcher, domain, query); len(groups) > 0 {
 			logger.WithField("groups", groups).Debugf("domain is allowlisted")
 
 			resp, err := r.next.Resolve(ctx, request)
@@ -380,13 +603,19 @@ func (r *BlockingResolver) handleDenylist(ctx context.Context, groupsToCheck []s
 		}
 
 		if allowlistOnlyAllowed {
-			resp, err := r.handleBlocked(logger, request, question, "BLOCKED (ALLOWLIST ONLY)")
+			resp, err := r.handleBlocked(ctx, logger, request, question, nil, "BLOCKED (ALLOWLIST ONLY)")
 
 			return true, resp, err
 		}
 
-		if groups := r.matches(groupsToCheck, r.denylistMatcher, domain); len(groups) > 0 {
-			resp, err := r.handleBlocked(logger, request, question, fmt.Sprintf("BLOCKED (%s)", strings.Join(groups, ",")))
+		if match, trigger := r.matchRPZNames(ctx, logger, request, groupsToCheck, domain); match != nil {
+			resp, err := r.handleRPZ(ctx, logger, request, question, match, trigger)
+
+			return true, resp, err
+		}
+
+		if groups := r.matches(groupsToCheck, r.denylistMatcher, domain, query); len(groups) > 0 {
+			resp, err := r.handleBlocked(ctx, logger, request, question, groups, fmt.Sprintf("BLOCKED (%s)", strings.Join(groups, ",")))
 
 			return true, resp, err
 		}
@@ -410,24 +639,69 @@ func (r *BlockingResolver) Resolve(ctx context.Context, request *model.Request)
 	respFromNext, err := r.next.Resolve(ctx, request)
 
 	if err == nil && len(groupsToCheck) > 0 && respFromNext.Res != nil {
+		query := filterQuery(request, request.Req.Question[0])
+
+		if match := r.matchRPZIP(groupsToCheck, respFromNext.Res.Answer); match != nil {
+			if match.Action == parsers.RPZActionPassthru {
+				return respFromNext, nil
+			}
+
+			return r.handleRPZ(ctx, logger, request, request.Req.Question[0], match, parsers.RPZTriggerIP)
+		}
+
 		for _, rr := range respFromNext.Res.Answer {
 			entryToCheck, tName := extractEntryToCheckFromResponse(rr)
 			if len(entryToCheck) > 0 {
 				logger := logger.WithField("response_entry", entryToCheck)
 
-				if groups := r.matches(groupsToCheck, r.allowlistMatcher, entryToCheck); len(groups) > 0 {
+				if groups := r.matches(groupsToCheck, r.allowlistMatcher, entryToCheck, query); len(groups) > 0 {
 					logger.WithField("groups", groups).Debugf("%s is allowlisted", tName)
-				} else if groups := r.matches(groupsToCheck, r.denylistMatcher, entryToCheck); len(groups) > 0 {
-					return r.handleBlocked(logger, request, request.Req.Question[0], fmt.Sprintf("BLOCKED %s (%s)", tName,
+				} else if groups := r.matches(groupsToCheck, r.denylistMatcher, entryToCheck, query); len(groups) > 0 {
+					return r.handleBlocked(ctx, logger, request, request.Req.Question[0], groups, fmt.Sprintf("BLOCKED %s (%s)", tName,
 						strings.Join(groups, ",")))
 				}
 			}
 		}
+
+		if r.geoIP != nil {
+			return r.handleGeoIP(ctx, logger, request, groupsToCheck, respFromNext)
+		}
 	}
 
 	return respFromNext, err
 }
 
+// handleGeoIP blocks the response or flags it with the country, if an answer IP is located in a country
+// configured for one of the groups
+func (r *BlockingResolver) handleGeoIP(ctx context.Context, logger *logrus.Entry,
+	request *model.Request, groupsToCheck []string, response *model.Response,
+) (*model.Response, error) {
+	country, action, groups := r.geoIP.match(groupsToCheck, response.Res.Answer)
+	if action == "" {
+		return response, nil
+	}
+
+	evt.Bus().Publish(evt.BlockingGeoIPMatched, country, action)
+
+	if action == geoIPActionBlock {
+		resp, err := r.handleBlocked(ctx, logger, request, request.Req.Question[0], groups,
+			fmt.Sprintf("BLOCKED GEOIP %s (%s)", country, strings.Join(groups, ",")))
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Country = country
+
+		return resp, nil
+	}
+
+	logger.WithField("groups", groups).Debugf("answer located in flagged country %s", country)
+
+	response.Country = country
+
+	return response, nil
+}
+
 func extractEntryToCheckFromResponse(rr dns.RR) (entryToCheck, tName string) {
 	switch v := rr.(type) {
 	case *dns.A:
@@ -463,6 +737,12 @@ func (r *BlockingResolver) groupsToCheckForClient(request *model.Request) []stri
 	defer r.status.lock.RUnlock()
 
 	var groups []string
+
+	if profile, ok := r.profiles[request.Profile]; ok && profile.BlockingGroups != nil {
+		// the profile replaces the groups by client
+		return r.enabledGroups(r.withoutPausedGroups(request, r.applySchedules(request, profile.BlockingGroups)))
+	}
+
 	// try client names
 	for _, cName := range request.ClientNames {
 		for blockGroup, groupsByName := range r.clientGroupsBlock {
@@ -472,23 +752,24 @@ func (r *BlockingResolver) groupsToCheckForClient(request *model.Request) []stri
 		}
 	}
 
-	// try IP
-	groupsByIP, found := r.clientGroupsBlock[request.ClientIP.String()]
-
-	if found {
+	// try IP, subnets and ranges, the longest prefix wins
+	if groupsByIP, found := r.clientGroupsByIP.LongestMatch(request.ClientIP); found {
 		groups = append(groups, groupsByIP...)
 	}
 
-	for clientIdentifier, groupsByCidr := range r.clientGroupsBlock {
-		// try CIDR
-		if util.CidrContainsIP(clientIdentifier, request.ClientIP) {
-			groups = append(groups, groupsByCidr...)
-		} else if isFQDN(clientIdentifier) && r.fqdnIPCache != nil {
+	// try MAC
+	if request.ClientMAC != nil {
+		groups = append(groups, r.clientGroupsBlock[request.ClientMAC.String()]...)
+	}
+
+	for clientIdentifier, groupsByFQDN := range r.clientGroupsBlock {
+		// try FQDN
+		if isFQDN(clientIdentifier) && r.fqdnIPCache != nil {
 			ips, _ := r.fqdnIPCache.Get(clientIdentifier)
 			if ips != nil {
 				for _, ip := range *ips {
 					if ip.Equal(request.ClientIP) {
-						groups = append(groups, groupsByCidr...)
+						groups = append(groups, groupsByFQDN...)
 					}
 				}
 			}
@@ -500,6 +781,11 @@ func (r *BlockingResolver) groupsToCheckForClient(request *model.Request) []stri
 		groups = r.clientGroupsBlock["default"]
 	}
 
+	return r.enabledGroups(r.withoutPausedGroups(request, r.applySchedules(request, groups)))
+}
+
+// returns the groups, which are not disabled, sorted
+func (r *BlockingResolver) enabledGroups(groups []string) []string {
 	var result []string
 
 	for _, g := range groups {
@@ -514,15 +800,24 @@ func (r *BlockingResolver) groupsToCheckForClient(request *model.Request) []stri
 }
 
 func (r *BlockingResolver) matches(groupsToCheck []string, m lists.Matcher,
-	domain string,
+	domain string, query *lists.FilterQuery,
 ) (group []string) {
 	if len(groupsToCheck) > 0 {
-		return m.Match(domain, groupsToCheck)
+		return m.Match(domain, groupsToCheck, query)
 	}
 
 	return []string{}
 }
 
+// filterQuery returns the client and type of the question, which adblock rules with modifiers are matched against
+func filterQuery(request *model.Request, question dns.Question) *lists.FilterQuery {
+	return &lists.FilterQuery{
+		ClientIP:    request.ClientIP,
+		ClientNames: request.ClientNames,
+		QType:       question.Qtype,
+	}
+}
+
 type blockHandler interface {
 	handleBlock(question dns.Question, response *dns.Msg)
 }
@@ -533,6 +828,13 @@ type zeroIPBlockHandler struct {
 
 type nxDomainBlockHandler struct{}
 
+type noDataBlockHandler struct{}
+
+type cnameBlockHandler struct {
+	target       string
+	BlockTimeSec uint32
+}
+
 type ipBlockHandler struct {
 	destinations    []net.IP
 	fallbackHandler blockHandler
@@ -562,11 +864,23 @@ func (b nxDomainBlockHandler) handleBlock(_ dns.Question, response *dns.Msg) {
 	response.Rcode = dns.RcodeNameError
 }
 
+func (b noDataBlockHandler) handleBlock(_ dns.Question, response *dns.Msg) {
+	response.Rcode = dns.RcodeSuccess
+}
+
+func (b cnameBlockHandler) handleBlock(question dns.Question, response *dns.Msg) {
+	cname := new(dns.CNAME)
+	cname.Hdr = dns.RR_Header{Class: dns.ClassINET, Ttl: b.BlockTimeSec, Rrtype: dns.TypeCNAME, Name: question.Name}
+	cname.Target = b.target
+
+	response.Answer = append(response.Answer, cname)
+}
+
 func (b ipBlockHandler) handleBlock(question dns.Question, response *dns.Msg) {
 	for _, ip := range b.destinations {
-		answer, _ := util.CreateAnswerFromQuestion(question, ip, b.BlockTimeSec)
+		if matchesQType(ip, question.Qtype) {
+			answer, _ := util.CreateAnswerFromQuestion(question, ip, b.BlockTimeSec)
 
-		if (question.Qtype == dns.TypeAAAA && ip.To4() == nil) || (question.Qtype == dns.TypeA && ip.To4() != nil) {
 			response.Answer = append(response.Answer, answer)
 		}
 	}
@@ -577,6 +891,36 @@ func (b ipBlockHandler) handleBlock(question dns.Question, response *dns.Msg) {
 	}
 }
 
+// usesFallback returns true if none of the destinations answers the question
+func (b ipBlockHandler) usesFallback(question dns.Question) bool {
+	for _, ip := range b.destinations {
+		if matchesQType(ip, question.Qtype) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchesQType(ip net.IP, qType uint16) bool {
+	return (qType == dns.TypeAAAA && ip.To4() == nil) || (qType == dns.TypeA && ip.To4() != nil)
+}
+
+// cnameTarget returns the target, if the handler answers the question with a CNAME,
+// e.g. the host name block type as fallback of the block page
+func cnameTarget(handler blockHandler, question dns.Question) (string, bool) {
+	switch h := handler.(type) {
+	case cnameBlockHandler:
+		return h.target, true
+	case ipBlockHandler:
+		if h.usesFallback(question) {
+			return cnameTarget(h.fallbackHandler, question)
+		}
+	}
+
+	return "", false
+}
+
 func (r *BlockingResolver) queryForFQIdentifierIPs(ctx context.Context, identifier string) (*[]net.IP, time.Duration) {
 	ctx, logger := r.logWith(ctx, func(logger *logrus.Entry) *logrus.Entry {
 		return log.WithPrefix(logger, "client_id_cache")
@@ -0,0 +1,126 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/Abiji-2020/bGuard/lists"
+	"github.com/Abiji-2020/bGuard/lists/parsers"
+	"github.com/Abiji-2020/bGuard/model"
+	"github.com/Abiji-2020/bGuard/util"
+
+	"github.com/miekg/dns"
+	"github.com/sirupsen/logrus"
+)
+
+// matchRPZNames matches the query name against the QNAME rules of the response policy zones, then the names of
+// its authoritative name servers against the NSDNAME rules. A failed name server lookup counts as no NSDNAME match.
+func (r *BlockingResolver) matchRPZNames(ctx context.Context, logger *logrus.Entry, request *model.Request,
+	groupsToCheck []string, domain string,
+) (*lists.RPZMatch, parsers.RPZTrigger) {
+	if match, ok := r.denylistMatcher.MatchRPZName(parsers.RPZTriggerQName, domain, groupsToCheck); ok {
+		return match, parsers.RPZTriggerQName
+	}
+
+	if !r.denylistMatcher.HasRPZTrigger(parsers.RPZTriggerNSDName, groupsToCheck) {
+		return nil, ""
+	}
+
+	nameServers, err := r.nameServersOf(ctx, request, domain)
+	if err != nil {
+		logger.Warnf("can't look up name servers of '%s' for NSDNAME rules: %v", domain, err)
+
+		return nil, ""
+	}
+
+	for _, ns := range nameServers {
+		if match, ok := r.denylistMatcher.MatchRPZName(parsers.RPZTriggerNSDName, ns, groupsToCheck); ok {
+			return match, parsers.RPZTriggerNSDName
+		}
+	}
+
+	return nil, ""
+}
+
+// nameServersOf returns the names of the authoritative name servers of the closest zone containing the domain
+func (r *BlockingResolver) nameServersOf(ctx context.Context, request *model.Request, domain string) ([]string, error) {
+	for name := domain; name != ""; {
+		resp, err := r.next.Resolve(ctx, subRequest(request, name, dns.TypeNS))
+		if err != nil {
+			return nil, err
+		}
+
+		var nameServers []string
+
+		for _, rr := range append(resp.Res.Answer, resp.Res.Ns...) {
+			if ns, ok := rr.(*dns.NS); ok {
+				nameServers = append(nameServers, util.ExtractDomainOnly(ns.Ns))
+			}
+		}
+
+		if len(nameServers) != 0 {
+			return nameServers, nil
+		}
+
+		_, name, _ = strings.Cut(name, ".")
+	}
+
+	return nil, nil
+}
+
+// matchRPZIP returns the RPZ-IP rule matching the first answer IP
+func (r *BlockingResolver) matchRPZIP(groupsToCheck []string, answer []dns.RR) *lists.RPZMatch {
+	for _, rr := range answer {
+		var ip net.IP
+
+		switch v := rr.(type) {
+		case *dns.A:
+			ip = v.A
+		case *dns.AAAA:
+			ip = v.AAAA
+		default:
+			continue
+		}
+
+		if match, ok := r.denylistMatcher.MatchRPZIP(ip, groupsToCheck); ok {
+			return match
+		}
+	}
+
+	return nil
+}
+
+// handleRPZ applies the action of the matching rule of a response policy zone
+func (r *BlockingResolver) handleRPZ(ctx context.Context, logger *logrus.Entry,
+	request *model.Request, question dns.Question, match *lists.RPZMatch, trigger parsers.RPZTrigger,
+) (*model.Response, error) {
+	logger = logger.WithFields(logrus.Fields{"policy": match.Policy, "rule": match.Name})
+	reason := fmt.Sprintf("BLOCKED RPZ %s %s (%s)",
+		strings.ToUpper(string(trigger)), strings.ToUpper(string(match.Action)), match.Policy)
+
+	var handler blockHandler
+
+	switch match.Action {
+	case parsers.RPZActionPassthru:
+		logger.Debug("query is passed through by response policy zone")
+
+		return r.next.Resolve(ctx, request)
+	case parsers.RPZActionDrop:
+		response := new(dns.Msg)
+		response.SetReply(request.Req)
+
+		logger.Debugf("dropping request '%s'", reason)
+
+		return &model.Response{Res: response, RType: model.ResponseTypeBLOCKED, Reason: reason, Drop: true}, nil
+	case parsers.RPZActionNoData:
+		handler = noDataBlockHandler{}
+	case parsers.RPZActionCNAME:
+		handler = cnameBlockHandler{target: dns.Fqdn(match.Target), BlockTimeSec: r.cfg.BlockTTL.SecondsU32()}
+	default:
+		handler = nxDomainBlockHandler{}
+	}
+
+	return r.respondBlocked(ctx, logger, request, question, handler, []string{match.Group}, reason)
+}
@@ -0,0 +1,144 @@
+package resolver
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Abiji-2020/bGuard/config"
+	"github.com/Abiji-2020/bGuard/evt"
+	"github.com/Abiji-2020/bGuard/model"
+
+	"golang.org/x/exp/maps"
+)
+
+// blockingSchedule turns denylist groups on or off for its clients during its windows
+type blockingSchedule struct {
+	config.BlockingSchedule
+	name string
+	// nil if the schedule applies to all clients
+	clients *clientMatcher[bool]
+}
+
+// newBlockingSchedules returns the schedules sorted by name
+func newBlockingSchedules(cfg map[string]config.BlockingSchedule) []blockingSchedule {
+	names := maps.Keys(cfg)
+	slices.Sort(names)
+
+	schedules := make([]blockingSchedule, 0, len(names))
+
+	for _, name := range names {
+		schedule := blockingSchedule{BlockingSchedule: cfg[name], name: name}
+
+		if len(schedule.Clients) != 0 || len(schedule.Profiles) != 0 {
+			schedule.clients = newClientMatcher[bool]()
+
+			for _, client := range schedule.Clients {
+				schedule.clients.add(client, true)
+			}
+		}
+
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules
+}
+
+// appliesTo returns true if the schedule applies to the client of the request
+func (s *blockingSchedule) appliesTo(request *model.Request) bool {
+	if s.clients == nil || slices.Contains(s.Profiles, request.Profile) {
+		return true
+	}
+
+	_, ok := s.clients.match(request)
+
+	return ok
+}
+
+// applySchedules adds the groups of active "block" schedules and removes the groups of
+// inactive "block" and active "allow" schedules, which apply to the client of the request
+func (r *BlockingResolver) applySchedules(request *model.Request, groups []string) []string {
+	now := time.Now()
+
+	for i := range r.schedules {
+		schedule := &r.schedules[i]
+
+		if !schedule.appliesTo(request) {
+			continue
+		}
+
+		active := schedule.IsActive(now)
+
+		switch {
+		case active && schedule.Action == config.ScheduleActionBlock:
+			for _, group := range schedule.Groups {
+				if !slices.Contains(groups, group) {
+					groups = append(slices.Clip(groups), group)
+				}
+			}
+
+		case active || schedule.Action == config.ScheduleActionBlock:
+			groups = slices.DeleteFunc(slices.Clone(groups), func(group string) bool {
+				return slices.Contains(schedule.Groups, group)
+			})
+		}
+	}
+
+	return groups
+}
+
+// activeSchedules returns the names of the schedules within one of their windows
+func (r *BlockingResolver) activeSchedules() []string {
+	var result []string
+
+	now := time.Now()
+
+	for i := range r.schedules {
+		if r.schedules[i].IsActive(now) {
+			result = append(result, r.schedules[i].name)
+		}
+	}
+
+	return result
+}
+
+// watchSchedules publishes the state of each schedule and its changes, checking at the start of every minute
+func (r *BlockingResolver) watchSchedules(ctx context.Context) {
+	_, logger := r.log(ctx)
+
+	states := make(map[string]bool, len(r.schedules))
+	timer := time.NewTimer(0)
+
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			now := time.Now()
+
+			for i := range r.schedules {
+				schedule := &r.schedules[i]
+				active := schedule.IsActive(now)
+
+				if previous, known := states[schedule.name]; known && previous == active {
+					continue
+				}
+
+				states[schedule.name] = active
+
+				if active {
+					logger.Infof("schedule '%s' is active", schedule.name)
+				} else {
+					logger.Infof("schedule '%s' is inactive", schedule.name)
+				}
+
+				evt.Bus().Publish(evt.BlockingScheduleChanged, schedule.name, active)
+			}
+
+			timer.Reset(time.Until(now.Truncate(time.Minute).Add(time.Minute)))
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
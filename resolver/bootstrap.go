@@ -100,7 +100,7 @@ func NewBootstrap(ctx context.Context, cfg *config.Config) (b *Bootstrap, err er
 	b.bootstraped = bootstraped
 
 	b.resolver = Chain(
-		NewFilteringResolver(cfg.Filtering),
+		NewFilteringResolver(cfg.Filtering, nil),
 		// false: no metrics, to not overwrite the main blocking resolver ones
 		newCachingResolver(ctx, cachingCfg, nil, false),
 		newParallelBestResolver(pbCfg, bootstraped.Resolvers()),
@@ -286,6 +286,12 @@ func newBootstrapedResolvers(
 			continue
 		}
 
+		if upstream.IsRecursive() {
+			multiErr = multierror.Append(multiErr, fmt.Errorf("item %d: '%s' can't be used for bootstrap", i, upstream))
+
+			continue
+		}
+
 		ips := make([]net.IP, 0, len(upstreamCfg.IPs)+1)
 
 		if ip := net.ParseIP(upstream.Host); ip != nil {
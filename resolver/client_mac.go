@@ -0,0 +1,184 @@
+package resolver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Abiji-2020/bGuard/model"
+	"github.com/Abiji-2020/bGuard/util"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// edns0MACOptionCode is the EDNS0 option dnsmasq (add-mac) and OpenWrt add with the client's MAC address
+	edns0MACOptionCode = 65001
+
+	procNetARP = "/proc/net/arp"
+
+	// neighborTableMaxAge is the time after which the neighbor table is reloaded
+	neighborTableMaxAge = 5 * time.Second
+)
+
+// macFromEdns0 returns the MAC address of the client added by a forwarding DNS server, or nil
+func macFromEdns0(request *model.Request) net.HardwareAddr {
+	opt := util.GetEdns0LocalOption(request.Req, edns0MACOptionCode)
+	if opt == nil {
+		return nil
+	}
+
+	// dnsmasq adds the address in binary format by default, or as text or base64 (add-mac=text, add-mac=base64)
+	if len(opt.Data) == 6 { //nolint:mnd
+		return net.HardwareAddr(opt.Data)
+	}
+
+	if mac, err := net.ParseMAC(string(opt.Data)); err == nil {
+		return mac
+	}
+
+	if data, err := base64.StdEncoding.DecodeString(string(opt.Data)); err == nil && len(data) == 6 { //nolint:mnd
+		return net.HardwareAddr(data)
+	}
+
+	return nil
+}
+
+// neighborTable looks up the MAC addresses of clients in the local network from the kernel's neighbor table.
+// Only directly connected clients can be found.
+type neighborTable struct {
+	lock     sync.RWMutex
+	entries  map[string]net.HardwareAddr
+	loadedAt time.Time
+	load     func(ctx context.Context) (map[string]net.HardwareAddr, error)
+	// concurrent lookups share a reload, which runs without holding the lock
+	reload singleflight.Group
+}
+
+func newNeighborTable() *neighborTable {
+	return &neighborTable{load: loadNeighbors}
+}
+
+// lookup returns the MAC address of the IP or nil, the table is reloaded if it is outdated
+func (t *neighborTable) lookup(ctx context.Context, ip net.IP) (net.HardwareAddr, error) {
+	t.lock.RLock()
+	entries, outdated := t.entries, time.Since(t.loadedAt) > neighborTableMaxAge
+	t.lock.RUnlock()
+
+	if outdated {
+		res, err, _ := t.reload.Do("", func() (any, error) {
+			entries, err := t.load(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			t.lock.Lock()
+			t.entries = entries
+			t.loadedAt = time.Now()
+			t.lock.Unlock()
+
+			return entries, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		entries = res.(map[string]net.HardwareAddr)
+	}
+
+	return entries[ip.String()], nil
+}
+
+// loadNeighbors reads the IPv4 entries of /proc/net/arp and the IPv4 and IPv6 entries of `ip neigh`
+func loadNeighbors(ctx context.Context) (map[string]net.HardwareAddr, error) {
+	entries := make(map[string]net.HardwareAddr)
+
+	var errs []error
+
+	if f, err := os.Open(procNetARP); err == nil {
+		errs = append(errs, parseProcNetARP(f, entries))
+
+		f.Close()
+	} else {
+		errs = append(errs, err)
+	}
+
+	if out, err := exec.CommandContext(ctx, "ip", "neigh", "show").Output(); err == nil {
+		errs = append(errs, parseIPNeigh(bytes.NewReader(out), entries))
+	} else {
+		errs = append(errs, err)
+	}
+
+	if len(entries) == 0 {
+		return entries, errors.Join(errs...)
+	}
+
+	return entries, nil
+}
+
+// parseProcNetARP reads /proc/net/arp:
+//
+//	IP address       HW type     Flags       HW address            Mask     Device
+//	192.168.1.1      0x1         0x2         00:11:22:33:44:55     *        eth0
+func parseProcNetARP(r io.Reader, entries map[string]net.HardwareAddr) error {
+	const minFields = 4
+
+	scanner := bufio.NewScanner(r)
+
+	// skip the header
+	scanner.Scan()
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < minFields || fields[2] == "0x0" {
+			// incomplete entry
+			continue
+		}
+
+		addNeighbor(entries, fields[0], fields[3])
+	}
+
+	return scanner.Err()
+}
+
+// parseIPNeigh reads the output of `ip neigh show`:
+//
+//	192.168.1.1 dev eth0 lladdr 00:11:22:33:44:55 REACHABLE
+//	fe80::1 dev eth0 lladdr 00:11:22:33:44:55 router STALE
+func parseIPNeigh(r io.Reader, entries map[string]net.HardwareAddr) error {
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+
+		for i := 1; i < len(fields)-1; i++ {
+			if fields[i] == "lladdr" {
+				addNeighbor(entries, fields[0], fields[i+1])
+
+				break
+			}
+		}
+	}
+
+	return scanner.Err()
+}
+
+func addNeighbor(entries map[string]net.HardwareAddr, ipStr, macStr string) {
+	ip := net.ParseIP(ipStr)
+	mac, err := net.ParseMAC(macStr)
+
+	if ip == nil || err != nil || bytes.Equal(mac, make([]byte, len(mac))) {
+		return
+	}
+
+	entries[ip.String()] = mac
+}
@@ -0,0 +1,72 @@
+package resolver
+
+import (
+	"net"
+
+	"github.com/Abiji-2020/bGuard/model"
+	"github.com/Abiji-2020/bGuard/trie"
+	"github.com/Abiji-2020/bGuard/util"
+)
+
+// clientMatcher finds the value assigned to the client of a request.
+// Clients are identified by MAC address, by client name or ID and by the longest IP prefix.
+type clientMatcher[T any] struct {
+	byMAC  map[string]T
+	byName []clientNamePattern[T]
+	byIP   *trie.IPTrie[T]
+}
+
+type clientNamePattern[T any] struct {
+	pattern string
+	value   T
+}
+
+func newClientMatcher[T any]() *clientMatcher[T] {
+	return &clientMatcher[T]{
+		byMAC: make(map[string]T),
+		byIP:  trie.NewIPTrie[T](),
+	}
+}
+
+// add assigns the value to a client name (with wildcards) or ID, IP, CIDR subnet, IP range or MAC address.
+// The first value of a client wins.
+func (m *clientMatcher[T]) add(client string, value T) {
+	if prefixes, ok := util.ParseIPPrefixes(client); ok {
+		for _, prefix := range prefixes {
+			if _, exists := m.byIP.Get(prefix); !exists {
+				m.byIP.Insert(prefix, value)
+			}
+		}
+
+		return
+	}
+
+	if mac, err := net.ParseMAC(client); err == nil {
+		if _, exists := m.byMAC[mac.String()]; !exists {
+			m.byMAC[mac.String()] = value
+		}
+
+		return
+	}
+
+	m.byName = append(m.byName, clientNamePattern[T]{pattern: client, value: value})
+}
+
+// match returns the value of the client of the request
+func (m *clientMatcher[T]) match(request *model.Request) (value T, ok bool) {
+	if request.ClientMAC != nil {
+		if value, ok = m.byMAC[request.ClientMAC.String()]; ok {
+			return value, true
+		}
+	}
+
+	for _, name := range request.ClientNames {
+		for _, client := range m.byName {
+			if util.ClientNameMatchesGroupName(client.pattern, name) {
+				return client.value, true
+			}
+		}
+	}
+
+	return m.byIP.LongestMatch(request.ClientIP)
+}
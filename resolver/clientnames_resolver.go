@@ -3,20 +3,25 @@ package resolver
 import (
 	"context"
 	"net"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/Abiji-2020/bGuard/cache/expirationcache"
 	"github.com/Abiji-2020/bGuard/config"
+	"github.com/Abiji-2020/bGuard/dhcp"
 	"github.com/Abiji-2020/bGuard/log"
 	"github.com/Abiji-2020/bGuard/model"
+	"github.com/Abiji-2020/bGuard/trie"
 	"github.com/Abiji-2020/bGuard/util"
 
 	"github.com/miekg/dns"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
 )
 
-// ClientNamesResolver tries to determine client name by asking responsible DNS server via rDNS (reverse lookup)
+// ClientNamesResolver tries to determine client name from the mapping, the DHCP leases
+// or by asking responsible DNS server via rDNS (reverse lookup)
 type ClientNamesResolver struct {
 	configurable[*config.ClientLookup]
 	NextResolver
@@ -24,11 +29,17 @@ type ClientNamesResolver struct {
 
 	cache            expirationcache.ExpiringCache[[]string]
 	externalResolver Resolver
+	leases           *dhcp.Leases
+	neighbors        *neighborTable
+
+	// client names of the mapping
+	namesByIP  *trie.IPTrie[[]string]
+	namesByMAC map[string][]string
 }
 
-// NewClientNamesResolver creates new resolver instance
+// NewClientNamesResolver creates new resolver instance, leases may be nil
 func NewClientNamesResolver(ctx context.Context,
-	cfg config.ClientLookup, upstreamsCfg config.Upstreams, bootstrap *Bootstrap,
+	cfg config.ClientLookup, upstreamsCfg config.Upstreams, bootstrap *Bootstrap, leases *dhcp.Leases,
 ) (cr *ClientNamesResolver, err error) {
 	var r Resolver
 	if !cfg.Upstream.IsDefault() {
@@ -46,6 +57,15 @@ func NewClientNamesResolver(ctx context.Context,
 			CleanupInterval: time.Hour,
 		}),
 		externalResolver: r,
+		leases:           leases,
+		neighbors:        newNeighborTable(),
+	}
+
+	cr.namesByIP, cr.namesByMAC = clientNamesMapping(cfg.ClientnameIPMapping)
+
+	if leases != nil {
+		// names of changed leases must not be served from the cache
+		leases.OnChange(cr.FlushCache)
 	}
 
 	return
@@ -60,6 +80,14 @@ func (r *ClientNamesResolver) LogConfig(logger *logrus.Entry) {
 
 // Resolve tries to resolve the client name from the ip address
 func (r *ClientNamesResolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
+	if r.cfg.MACLookup {
+		request.ClientMAC = r.getClientMAC(ctx, request)
+
+		if request.ClientMAC != nil {
+			ctx, _ = log.CtxWithFields(ctx, logrus.Fields{"client_mac": request.ClientMAC.String()})
+		}
+	}
+
 	clientNames := r.getClientNames(ctx, request)
 
 	request.ClientNames = clientNames
@@ -79,7 +107,12 @@ func (r *ClientNamesResolver) getClientNames(ctx context.Context, request *model
 		return []string{}
 	}
 
-	c, _ := r.cache.Get(ip.String())
+	cacheKey := ip.String()
+	if request.ClientMAC != nil {
+		cacheKey += "/" + request.ClientMAC.String()
+	}
+
+	c, _ := r.cache.Get(cacheKey)
 	if c != nil {
 		// return copy here, since we can't control all usages here
 		cpy := make([]string, len(*c))
@@ -88,13 +121,38 @@ func (r *ClientNamesResolver) getClientNames(ctx context.Context, request *model
 		return cpy
 	}
 
-	names := r.resolveClientNames(ctx, ip)
+	names := r.resolveClientNames(ctx, ip, request.ClientMAC)
 
-	r.cache.Put(ip.String(), &names, time.Hour)
+	r.cache.Put(cacheKey, &names, time.Hour)
 
 	return names
 }
 
+// returns the MAC address of the client from the EDNS0 option of a trusted forwarder or the neighbor table
+func (r *ClientNamesResolver) getClientMAC(ctx context.Context, request *model.Request) net.HardwareAddr {
+	var mac net.HardwareAddr
+
+	// any client could add the option to take the MAC address of another client
+	if r.cfg.IsMACForwarder(request.ClientIP) {
+		mac = macFromEdns0(request)
+	}
+
+	// don't forward the MAC address to the upstream servers
+	util.StripEdns0LocalOption(request.Req, edns0MACOptionCode)
+
+	if mac != nil || request.ClientIP == nil {
+		return mac
+	}
+
+	mac, err := r.neighbors.lookup(ctx, request.ClientIP)
+	if err != nil {
+		_, logger := r.log(ctx)
+		logger.Debug("can't read neighbor table: ", err)
+	}
+
+	return mac
+}
+
 func extractClientNamesFromAnswer(answer []dns.RR, fallbackIP net.IP) (clientNames []string) {
 	for _, answer := range answer {
 		if t, ok := answer.(*dns.PTR); ok {
@@ -110,16 +168,22 @@ func extractClientNamesFromAnswer(answer []dns.RR, fallbackIP net.IP) (clientNam
 	return
 }
 
-// tries to resolve client name from mapping, performs reverse DNS lookup otherwise
-func (r *ClientNamesResolver) resolveClientNames(ctx context.Context, ip net.IP) (result []string) {
+// tries to resolve client name from mapping and DHCP leases, performs reverse DNS lookup otherwise
+func (r *ClientNamesResolver) resolveClientNames(ctx context.Context, ip net.IP, mac net.HardwareAddr) (result []string) {
 	ctx, logger := r.log(ctx)
 
 	// try client mapping first
-	result = r.getNameFromIPMapping(ip, result)
+	result = r.getNameFromIPMapping(ip, mac, result)
 	if len(result) > 0 {
 		return result
 	}
 
+	if name := r.getNameFromLeases(ip, mac); name != "" {
+		logger.WithField("client_names", name).Debug("resolved client name from DHCP lease")
+
+		return []string{name}
+	}
+
 	if r.externalResolver == nil {
 		return []string{ip.String()}
 	}
@@ -155,18 +219,77 @@ func (r *ClientNamesResolver) resolveClientNames(ctx context.Context, ip net.IP)
 	return result
 }
 
-func (r *ClientNamesResolver) getNameFromIPMapping(ip net.IP, result []string) []string {
-	for name, ips := range r.cfg.ClientnameIPMapping {
-		for _, i := range ips {
-			if ip.String() == i.String() {
-				result = append(result, name)
+// clientNamesMapping indexes the names of the mapping by IP prefix and by MAC address
+func clientNamesMapping(mapping map[string][]config.ClientAddress) (*trie.IPTrie[[]string], map[string][]string) {
+	byIP := trie.NewIPTrie[[]string]()
+	byMAC := make(map[string][]string)
+
+	// sorted for a stable order of the names
+	names := maps.Keys(mapping)
+	slices.Sort(names)
+
+	for _, name := range names {
+		for _, address := range mapping[name] {
+			if address.MAC != nil {
+				byMAC[address.MAC.String()] = appendUnique(byMAC[address.MAC.String()], name)
+			}
+
+			for _, prefix := range address.Prefixes {
+				names, _ := byIP.Get(prefix)
+				byIP.Insert(prefix, appendUnique(names, name))
 			}
 		}
 	}
 
+	return byIP, byMAC
+}
+
+func appendUnique(names []string, name string) []string {
+	if slices.Contains(names, name) {
+		return names
+	}
+
+	return append(names, name)
+}
+
+// returns the names mapped to the MAC address and to the longest prefix containing the IP
+func (r *ClientNamesResolver) getNameFromIPMapping(ip net.IP, mac net.HardwareAddr, result []string) []string {
+	if mac != nil {
+		result = append(result, r.namesByMAC[mac.String()]...)
+	}
+
+	if names, ok := r.namesByIP.LongestMatch(ip); ok {
+		for _, name := range names {
+			result = appendUnique(result, name)
+		}
+	}
+
 	return result
 }
 
+// returns the host name of the client's lease by IP, or by MAC for clients with changing IPv6 addresses
+func (r *ClientNamesResolver) getNameFromLeases(ip net.IP, mac net.HardwareAddr) string {
+	if r.leases == nil {
+		return ""
+	}
+
+	if lease := r.leases.ByIP(ip); lease != nil && lease.Hostname != "" {
+		return lease.Hostname
+	}
+
+	if mac == nil {
+		return ""
+	}
+
+	for _, lease := range r.leases.ByMAC(mac) {
+		if lease.Hostname != "" {
+			return lease.Hostname
+		}
+	}
+
+	return ""
+}
+
 // FlushCache reset client name cache
 func (r *ClientNamesResolver) FlushCache() {
 	r.cache.Clear()
@@ -0,0 +1,152 @@
+package resolver
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Abiji-2020/bGuard/config"
+	"github.com/Abiji-2020/bGuard/dhcp"
+	"github.com/Abiji-2020/bGuard/model"
+	"github.com/Abiji-2020/bGuard/util"
+
+	"github.com/miekg/dns"
+	"github.com/sirupsen/logrus"
+)
+
+// DHCPLeasesResolver answers A, AAAA and PTR queries for the host names of DHCP leases
+type DHCPLeasesResolver struct {
+	configurable[*config.DHCPLeases]
+	NextResolver
+	typed
+
+	leases *dhcp.Leases
+	// lower case domain without dots at the start and end, empty if no records are created
+	domain string
+}
+
+// NewDHCPLeasesResolver creates new resolver instance, which loads the lease files and watches them for changes
+func NewDHCPLeasesResolver(ctx context.Context, cfg config.DHCPLeases) *DHCPLeasesResolver {
+	r := &DHCPLeasesResolver{
+		configurable: withConfig(&cfg),
+		typed:        withType("dhcp_leases"),
+
+		domain: strings.Trim(strings.ToLower(cfg.Domain), "."),
+	}
+
+	if !r.IsEnabled() {
+		return r
+	}
+
+	r.leases = dhcp.NewLeases(cfg.Sources)
+
+	_, logger := r.log(ctx)
+
+	// the DHCP server may create missing files later, so errors are not fatal
+	if err := r.leases.Load(); err != nil {
+		logger.Warn("can't load DHCP leases: ", err)
+	}
+
+	if cfg.CheckInterval.IsAboveZero() {
+		go r.leases.Watch(ctx, cfg.CheckInterval.ToDuration())
+	}
+
+	return r
+}
+
+// Leases returns the leases of the lease files, nil if disabled
+func (r *DHCPLeasesResolver) Leases() *dhcp.Leases {
+	return r.leases
+}
+
+// LogConfig implements `config.Configurable`.
+func (r *DHCPLeasesResolver) LogConfig(logger *logrus.Entry) {
+	r.cfg.LogConfig(logger)
+
+	logger.Infof("leases = %d", r.leases.Len())
+}
+
+// Resolve answers queries for leased host names in the configured domain
+func (r *DHCPLeasesResolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
+	if !r.IsEnabled() || r.domain == "" {
+		return r.next.Resolve(ctx, request)
+	}
+
+	ctx, logger := r.log(ctx)
+
+	question := request.Req.Question[0]
+
+	var answer []dns.RR
+
+	switch question.Qtype {
+	case dns.TypeA, dns.TypeAAAA:
+		answer = r.resolveHost(question)
+	case dns.TypePTR:
+		answer = r.resolvePTR(question)
+	}
+
+	if answer != nil {
+		response := new(dns.Msg)
+		response.SetReply(request.Req)
+		response.Answer = answer
+
+		logger.WithFields(logrus.Fields{
+			"answer": util.AnswerToString(answer),
+			"domain": util.Obfuscate(question.Name),
+		}).Debugf("returning DHCP lease")
+
+		return &model.Response{Res: response, RType: model.ResponseTypeCUSTOMDNS, Reason: "DHCP LEASE"}, nil
+	}
+
+	logger.WithField("next_resolver", Name(r.next)).Trace("go to next resolver")
+
+	return r.next.Resolve(ctx, request)
+}
+
+// resolveHost returns the addresses of the leases, an empty answer if the host has none of the queried type
+// and nil if the name is not a leased host
+func (r *DHCPLeasesResolver) resolveHost(question dns.Question) []dns.RR {
+	host, ok := strings.CutSuffix(util.ExtractDomain(question), "."+r.domain)
+	if !ok || strings.Contains(host, ".") {
+		return nil
+	}
+
+	leases := r.leases.ByHostname(host)
+	if len(leases) == 0 {
+		return nil
+	}
+
+	answer := []dns.RR{}
+
+	for _, lease := range leases {
+		isIPv4 := lease.IP.To4() != nil
+		if isIPv4 != (question.Qtype == dns.TypeA) {
+			continue
+		}
+
+		rr, err := util.CreateAnswerFromQuestion(question, lease.IP, r.cfg.TTL.SecondsU32())
+		if err == nil {
+			answer = append(answer, rr)
+		}
+	}
+
+	return answer
+}
+
+func (r *DHCPLeasesResolver) resolvePTR(question dns.Question) []dns.RR {
+	ip, err := util.ParseIPFromArpaAddr(question.Name)
+	if err != nil {
+		return nil
+	}
+
+	lease := r.leases.ByIP(ip)
+	if lease == nil || lease.Hostname == "" {
+		return nil
+	}
+
+	name := dns.Fqdn(lease.HostLabel() + "." + r.domain)
+	if _, ok := dns.IsDomainName(name); !ok {
+		return nil
+	}
+
+	return []dns.RR{&dns.PTR{Hdr: util.CreateHeader(question, r.cfg.TTL.SecondsU32()), Ptr: name}}
+}
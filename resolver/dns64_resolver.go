@@ -0,0 +1,278 @@
+package resolver
+
+import (
+	"context"
+	"math"
+	"net"
+
+	"github.com/Abiji-2020/bGuard/config"
+	"github.com/Abiji-2020/bGuard/model"
+	"github.com/Abiji-2020/bGuard/util"
+	"github.com/miekg/dns"
+	"github.com/sirupsen/logrus"
+)
+
+// https://www.rfc-editor.org/rfc/rfc6052.html#section-2.2
+const (
+	nat64ReservedOctet = 8 // bits 64 to 71 must be zero
+
+	bitsPerByte = 8
+)
+
+// DNS64Resolver synthesizes AAAA records from A records for IPv6-only clients (RFC 6147)
+type DNS64Resolver struct {
+	configurable[*config.DNS64]
+	NextResolver
+	typed
+
+	prefix net.IPNet
+}
+
+// NewDNS64Resolver creates a new resolver instance
+func NewDNS64Resolver(cfg config.DNS64) *DNS64Resolver {
+	return &DNS64Resolver{
+		configurable: withConfig(&cfg),
+		typed:        withType("dns64"),
+
+		prefix: net.IPNet(cfg.Prefix),
+	}
+}
+
+// Resolve synthesizes AAAA answers for domains which only have A records
+// and answers PTR queries for synthesized addresses
+func (r *DNS64Resolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
+	if !r.IsEnabled() || !r.matchesClient(request) {
+		return r.next.Resolve(ctx, request)
+	}
+
+	ctx, logger := r.log(ctx)
+
+	switch request.Req.Question[0].Qtype {
+	case dns.TypeAAAA:
+		return r.resolveAAAA(ctx, logger, request)
+	case dns.TypePTR:
+		return r.resolvePTR(ctx, logger, request)
+	}
+
+	return r.next.Resolve(ctx, request)
+}
+
+func (r *DNS64Resolver) resolveAAAA(
+	ctx context.Context, logger *logrus.Entry, request *model.Request,
+) (*model.Response, error) {
+	response, err := r.next.Resolve(ctx, request)
+	if err != nil || !r.needsSynthesis(request, response) {
+		return response, err
+	}
+
+	aRequest := subRequest(request, request.Req.Question[0].Name, dns.TypeA)
+
+	aResponse, err := r.next.Resolve(ctx, aRequest)
+	if err != nil || aResponse.Res.Rcode != dns.RcodeSuccess {
+		// keep the original answer: the client can't do anything with a failed A lookup
+		return response, nil //nolint:nilerr
+	}
+
+	maxTTL := negativeTTL(response.Res)
+	answer := make([]dns.RR, 0, len(aResponse.Res.Answer))
+	synthesized := 0
+
+	for _, rr := range aResponse.Res.Answer {
+		switch v := rr.(type) {
+		case *dns.CNAME:
+			answer = append(answer, v)
+		case *dns.A:
+			if v.A.IsUnspecified() || v.A.IsLoopback() {
+				continue
+			}
+
+			answer = append(answer, &dns.AAAA{
+				Hdr: dns.RR_Header{
+					Name:   v.Hdr.Name,
+					Rrtype: dns.TypeAAAA,
+					Class:  v.Hdr.Class,
+					Ttl:    min(v.Hdr.Ttl, maxTTL),
+				},
+				AAAA: r.synthesize(v.A),
+			})
+
+			synthesized++
+		}
+	}
+
+	if synthesized == 0 {
+		return response, nil
+	}
+
+	logger.WithField("domain", util.Obfuscate(request.Req.Question[0].Name)).
+		Debugf("synthesized %d AAAA record(s)", synthesized)
+
+	res := new(dns.Msg)
+	res.SetReply(request.Req)
+	res.RecursionAvailable = aResponse.Res.RecursionAvailable
+	res.Answer = answer
+
+	return &model.Response{Res: res, RType: aResponse.RType, Reason: "SYNTHESIZED (dns64)"}, nil
+}
+
+// needsSynthesis returns true if the AAAA response is empty and the client accepts synthesized records
+func (r *DNS64Resolver) needsSynthesis(request *model.Request, response *model.Response) bool {
+	if response.Res.Rcode != dns.RcodeSuccess {
+		return false
+	}
+
+	for _, rr := range response.Res.Answer {
+		if rr.Header().Rrtype == dns.TypeAAAA {
+			return false
+		}
+	}
+
+	// a validating client would reject the synthesized records (RFC 6147 section 5.5)
+	if opt := request.Req.IsEdns0(); request.Req.CheckingDisabled && opt != nil && opt.Do() {
+		return false
+	}
+
+	return !isSubDomainOfAny(request.Req.Question[0].Name, r.cfg.ExcludeDomains)
+}
+
+func (r *DNS64Resolver) resolvePTR(
+	ctx context.Context, logger *logrus.Entry, request *model.Request,
+) (*model.Response, error) {
+	question := request.Req.Question[0]
+
+	ip, err := util.ParseIPFromArpaAddr(dns.CanonicalName(question.Name))
+	if err != nil || ip.To4() != nil || !r.prefix.Contains(ip) {
+		return r.next.Resolve(ctx, request)
+	}
+
+	target, err := dns.ReverseAddr(r.extract(ip).String())
+	if err != nil {
+		return r.next.Resolve(ctx, request)
+	}
+
+	ptrResponse, err := r.next.Resolve(ctx, subRequest(request, target, dns.TypePTR))
+	if err != nil {
+		return nil, err
+	}
+
+	logger.WithField("domain", util.Obfuscate(question.Name)).Debugf("PTR for synthesized address mapped to %s", target)
+
+	res := new(dns.Msg)
+	res.SetReply(request.Req)
+	res.Rcode = ptrResponse.Res.Rcode
+	res.RecursionAvailable = ptrResponse.Res.RecursionAvailable
+	res.Answer = append([]dns.RR{&dns.CNAME{
+		Hdr:    dns.RR_Header{Name: question.Name, Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: minTTL(ptrResponse.Res)},
+		Target: target,
+	}}, ptrResponse.Res.Answer...)
+	res.Ns = ptrResponse.Res.Ns
+
+	return &model.Response{Res: res, RType: ptrResponse.RType, Reason: "SYNTHESIZED (dns64)"}, nil
+}
+
+// synthesize embeds an IPv4 address into the NAT64 prefix (RFC 6052 section 2.2)
+func (r *DNS64Resolver) synthesize(ip4 net.IP) net.IP {
+	ones, _ := r.prefix.Mask.Size()
+	v4 := ip4.To4()
+
+	result := make(net.IP, net.IPv6len)
+	copy(result, r.prefix.IP)
+
+	pos := ones / bitsPerByte
+
+	for _, b := range v4 {
+		if pos == nat64ReservedOctet {
+			pos++
+		}
+
+		result[pos] = b
+		pos++
+	}
+
+	return result
+}
+
+// extract is the reverse of synthesize
+func (r *DNS64Resolver) extract(ip6 net.IP) net.IP {
+	ones, _ := r.prefix.Mask.Size()
+	v6 := ip6.To16()
+
+	result := make(net.IP, net.IPv4len)
+	pos := ones / bitsPerByte
+
+	for i := range result {
+		if pos == nat64ReservedOctet {
+			pos++
+		}
+
+		result[i] = v6[pos]
+		pos++
+	}
+
+	return result
+}
+
+func (r *DNS64Resolver) matchesClient(request *model.Request) bool {
+	for _, client := range r.cfg.Clients {
+		if ip := net.ParseIP(client); ip != nil {
+			if ip.Equal(request.ClientIP) {
+				return true
+			}
+
+			continue
+		}
+
+		if util.CidrContainsIP(client, request.ClientIP) {
+			return true
+		}
+
+		for _, name := range request.ClientNames {
+			if util.ClientNameMatchesGroupName(client, name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// subRequest creates a copy of request asking for a different name and type
+func subRequest(request *model.Request, name string, qType uint16) *model.Request {
+	req := request.Req.Copy()
+	req.Question[0].Name = dns.Fqdn(name)
+	req.Question[0].Qtype = qType
+
+	sub := *request
+	sub.Req = req
+
+	return &sub
+}
+
+// negativeTTL returns the negative caching TTL of a NODATA response (RFC 2308 section 5)
+func negativeTTL(msg *dns.Msg) uint32 {
+	for _, rr := range msg.Ns {
+		if soa, ok := rr.(*dns.SOA); ok {
+			return min(soa.Hdr.Ttl, soa.Minttl)
+		}
+	}
+
+	return math.MaxUint32
+}
+
+func minTTL(msg *dns.Msg) uint32 {
+	ttl := uint32(math.MaxUint32)
+
+	for _, rr := range msg.Answer {
+		ttl = min(ttl, rr.Header().Ttl)
+	}
+
+	if len(msg.Answer) == 0 {
+		ttl = negativeTTL(msg)
+	}
+
+	if ttl == math.MaxUint32 {
+		return 0
+	}
+
+	return ttl
+}
@@ -14,18 +14,28 @@ type FilteringResolver struct {
 	configurable[*config.Filtering]
 	NextResolver
 	typed
+
+	profiles config.Profiles
 }
 
-func NewFilteringResolver(cfg config.Filtering) *FilteringResolver {
+// NewFilteringResolver creates new resolver instance, profiles may replace the query types per client
+func NewFilteringResolver(cfg config.Filtering, profiles config.Profiles) *FilteringResolver {
 	return &FilteringResolver{
 		configurable: withConfig(&cfg),
 		typed:        withType("filtering"),
+
+		profiles: profiles,
 	}
 }
 
 func (r *FilteringResolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
+	queryTypes := r.cfg.QueryTypes
+	if profile, ok := r.profiles[request.Profile]; ok && profile.FilterQueryTypes != nil {
+		queryTypes = profile.FilterQueryTypes
+	}
+
 	qType := request.Req.Question[0].Qtype
-	if r.cfg.QueryTypes.Contains(dns.Type(qType)) {
+	if queryTypes.Contains(dns.Type(qType)) {
 		response := new(dns.Msg)
 		response.SetRcode(request.Req, dns.RcodeSuccess)
 
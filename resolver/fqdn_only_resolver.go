@@ -14,17 +14,30 @@ type FQDNOnlyResolver struct {
 	configurable[*config.FQDNOnly]
 	NextResolver
 	typed
+
+	profiles config.Profiles
 }
 
-func NewFQDNOnlyResolver(cfg config.FQDNOnly) *FQDNOnlyResolver {
+// NewFQDNOnlyResolver creates new resolver instance, profiles may enable or disable it per client
+func NewFQDNOnlyResolver(cfg config.FQDNOnly, profiles config.Profiles) *FQDNOnlyResolver {
 	return &FQDNOnlyResolver{
 		configurable: withConfig(&cfg),
 		typed:        withType("fqdn_only"),
+
+		profiles: profiles,
+	}
+}
+
+func (r *FQDNOnlyResolver) isEnabledFor(request *model.Request) bool {
+	if profile, ok := r.profiles[request.Profile]; ok && profile.FQDNOnly != nil {
+		return *profile.FQDNOnly
 	}
+
+	return r.IsEnabled()
 }
 
 func (r *FQDNOnlyResolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
-	if r.IsEnabled() {
+	if r.isEnabledFor(request) {
 		domainFromQuestion := util.ExtractDomain(request.Req.Question[0])
 		if !strings.Contains(domainFromQuestion, ".") {
 			response := new(dns.Msg)
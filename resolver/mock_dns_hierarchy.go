@@ -0,0 +1,152 @@
+package resolver
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/Abiji-2020/bGuard/util"
+	"github.com/miekg/dns"
+)
+
+// MockDNSHierarchy is a fake tree of authoritative servers (root, TLDs, ...) to test recursive resolution.
+// Each zone is served by its own MockUDPUpstreamServer.
+type MockDNSHierarchy struct {
+	zones   []*mockZone
+	servers map[string]string
+}
+
+type mockZone struct {
+	name    string
+	ip      net.IP
+	records []dns.RR
+}
+
+func NewMockDNSHierarchy() *MockDNSHierarchy {
+	return &MockDNSHierarchy{
+		servers: make(map[string]string),
+	}
+}
+
+// WithZone adds a zone served by a name server with the IP nsIP.
+//
+// Records use the zone file format. Delegations to child zones are NS records for the child,
+// optionally with A/AAAA glue records.
+func (h *MockDNSHierarchy) WithZone(zone, nsIP string, records ...string) *MockDNSHierarchy {
+	z := &mockZone{
+		name: dns.CanonicalName(zone),
+		ip:   net.ParseIP(nsIP),
+	}
+
+	for _, record := range records {
+		rr, err := dns.NewRR(record)
+		util.FatalOnError("can't create RR", err)
+
+		z.records = append(z.records, rr)
+	}
+
+	h.zones = append(h.zones, z)
+
+	return h
+}
+
+// Start starts a local server for each zone
+func (h *MockDNSHierarchy) Start() *MockDNSHierarchy {
+	for _, zone := range h.zones {
+		upstream := NewMockUDPUpstreamServer().WithAnswerFn(zone.answer).Start()
+
+		h.servers[zone.ip.String()] = net.JoinHostPort(upstream.Host, strconv.Itoa(int(upstream.Port)))
+	}
+
+	return h
+}
+
+// RootHints returns the IPs of the servers of the root zone
+func (h *MockDNSHierarchy) RootHints() []net.IP {
+	var hints []net.IP
+
+	for _, zone := range h.zones {
+		if zone.name == "." {
+			hints = append(hints, zone.ip)
+		}
+	}
+
+	return hints
+}
+
+// Attach makes r send its queries to the local servers instead of the real ones
+func (h *MockDNSHierarchy) Attach(r *RecursiveResolver) {
+	r.rootServers = h.RootHints()
+	r.serverAddr = func(ip net.IP) string {
+		if addr, ok := h.servers[ip.String()]; ok {
+			return addr
+		}
+
+		// unknown server: unreachable address
+		return net.JoinHostPort("127.0.0.1", "9")
+	}
+}
+
+func (z *mockZone) answer(request *dns.Msg) *dns.Msg {
+	response := new(dns.Msg)
+	question := request.Question[0]
+	qName := dns.CanonicalName(question.Name)
+
+	if !dns.IsSubDomain(z.name, qName) {
+		response.Rcode = dns.RcodeRefused
+
+		return response
+	}
+
+	// delegation to a child zone
+	for _, rr := range z.records {
+		owner := dns.CanonicalName(rr.Header().Name)
+
+		if rr.Header().Rrtype == dns.TypeNS && owner != z.name && dns.IsSubDomain(owner, qName) {
+			response.Ns = append(response.Ns, rr)
+
+			for _, glue := range z.records {
+				if strings.EqualFold(glue.Header().Name, rr.(*dns.NS).Ns) {
+					response.Extra = append(response.Extra, glue)
+				}
+			}
+		}
+	}
+
+	if len(response.Ns) > 0 {
+		return response
+	}
+
+	response.Authoritative = true
+
+	nameExists := false
+
+	for _, rr := range z.records {
+		owner := dns.CanonicalName(rr.Header().Name)
+
+		if dns.IsSubDomain(qName, owner) {
+			nameExists = true
+		}
+
+		if owner == qName && (rr.Header().Rrtype == question.Qtype || rr.Header().Rrtype == dns.TypeCNAME) {
+			response.Answer = append(response.Answer, rr)
+		}
+	}
+
+	if len(response.Answer) > 0 {
+		return response
+	}
+
+	if !nameExists && qName != z.name {
+		response.Rcode = dns.RcodeNameError
+	}
+
+	soa, err := dns.NewRR(fmt.Sprintf("%s 60 IN SOA ns.%s hostmaster.%s 1 60 60 60 60",
+		z.name, strings.TrimPrefix(z.name, "."), strings.TrimPrefix(z.name, ".")))
+	util.FatalOnError("can't create SOA", err)
+
+	response.Ns = append(response.Ns, soa)
+
+	return response
+}
@@ -0,0 +1,98 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Abiji-2020/bGuard/config"
+	"github.com/Abiji-2020/bGuard/log"
+	"github.com/Abiji-2020/bGuard/model"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
+)
+
+// ProfileResolver assigns the client profile to the request.
+// Listeners bound to a profile take precedence, then clients are identified by MAC address,
+// by client name or ID and by the longest IP prefix.
+type ProfileResolver struct {
+	configurable[*config.Profiles]
+	NextResolver
+	typed
+
+	listeners []profileListener
+	clients   *clientMatcher[string]
+}
+
+type profileListener struct {
+	config.ProfileListener
+	profile string
+}
+
+// matches returns true if the request was received by the listener
+func (l *profileListener) matches(request *model.Request) bool {
+	if l.Path != "" {
+		return strings.TrimSuffix(request.Listener, "/") == strings.TrimSuffix(l.Path, "/")
+	}
+
+	host, port, err := net.SplitHostPort(request.Listener)
+	if err != nil || port != strconv.Itoa(int(l.Port)) {
+		return false
+	}
+
+	return l.IP == nil || l.IP.Equal(net.ParseIP(host))
+}
+
+// NewProfileResolver creates new resolver instance
+func NewProfileResolver(cfg config.Profiles) *ProfileResolver {
+	r := &ProfileResolver{
+		configurable: withConfig(&cfg),
+		typed:        withType("profiles"),
+
+		clients: newClientMatcher[string](),
+	}
+
+	// sorted, so the first profile wins if a client is assigned multiple times
+	names := maps.Keys(cfg)
+	slices.Sort(names)
+
+	for _, name := range names {
+		for _, client := range cfg[name].Clients {
+			r.clients.add(client, name)
+		}
+
+		for _, listener := range cfg[name].Listeners {
+			r.listeners = append(r.listeners, profileListener{ProfileListener: listener, profile: name})
+		}
+	}
+
+	return r
+}
+
+// Resolve assigns the profile of the client, unless the request already has one
+func (r *ProfileResolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
+	if r.IsEnabled() && request.Profile == "" {
+		request.Profile = r.profileOf(request)
+	}
+
+	if request.Profile != "" {
+		ctx, _ = log.CtxWithFields(ctx, logrus.Fields{"profile": request.Profile})
+	}
+
+	return r.next.Resolve(ctx, request)
+}
+
+func (r *ProfileResolver) profileOf(request *model.Request) string {
+	for _, listener := range r.listeners {
+		if listener.matches(request) {
+			return listener.profile
+		}
+	}
+
+	profile, _ := r.clients.match(request)
+
+	return profile
+}
@@ -187,6 +187,7 @@ func (r *QueryLoggingResolver) createLogEntry(request *model.Request, response *
 			entry.ResponseReason = response.Reason
 			entry.ResponseType = response.RType.String()
 			entry.ResponseCode = dns.RcodeToString[response.Res.Rcode]
+			entry.Country = response.Country
 
 		case config.QueryLogFieldResponseAnswer:
 			entry.Answer = util.AnswerToString(response.Res.Answer)
@@ -0,0 +1,97 @@
+package resolver
+
+import (
+	"context"
+	"net"
+
+	"github.com/Abiji-2020/bGuard/config"
+	"github.com/Abiji-2020/bGuard/model"
+	"github.com/Abiji-2020/bGuard/util"
+	"github.com/miekg/dns"
+)
+
+// RebindingResolver protects against DNS rebinding attacks by filtering answers
+// of public domains which point to private addresses
+type RebindingResolver struct {
+	configurable[*config.RebindingProtection]
+	NextResolver
+	typed
+}
+
+// NewRebindingResolver creates a new resolver instance
+func NewRebindingResolver(cfg config.RebindingProtection) *RebindingResolver {
+	return &RebindingResolver{
+		configurable: withConfig(&cfg),
+		typed:        withType("rebinding_protection"),
+	}
+}
+
+// Resolve removes private addresses from the response of the next resolver
+func (r *RebindingResolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
+	response, err := r.next.Resolve(ctx, request)
+	if err != nil || !r.IsEnabled() || r.isAllowed(request, response) {
+		return response, err
+	}
+
+	answer := make([]dns.RR, 0, len(response.Res.Answer))
+
+	for _, rr := range response.Res.Answer {
+		if ip := answerIP(rr); ip != nil && isPrivateIP(ip) {
+			continue
+		}
+
+		answer = append(answer, rr)
+	}
+
+	if len(answer) == len(response.Res.Answer) {
+		return response, nil
+	}
+
+	_, logger := r.log(ctx)
+
+	logger.WithField("domain", util.Obfuscate(request.Req.Question[0].Name)).
+		Warnf("answer contains private addresses, possible rebinding attack (action: %s)", r.cfg.Action)
+
+	res := response.Res.Copy()
+
+	switch r.cfg.Action {
+	case config.RebindingActionNxDomain:
+		res.Rcode = dns.RcodeNameError
+		res.Answer = nil
+	case config.RebindingActionDrop:
+		res.Answer = answer
+	}
+
+	return &model.Response{Res: res, RType: model.ResponseTypeBLOCKED, Reason: "BLOCKED (rebinding)"}, nil
+}
+
+func (r *RebindingResolver) isAllowed(request *model.Request, response *model.Response) bool {
+	switch response.RType {
+	case model.ResponseTypeSPECIAL:
+		// localhost and friends
+		return true
+	case model.ResponseTypeCONDITIONAL:
+		if r.cfg.AllowConditional {
+			return true
+		}
+	}
+
+	return isSubDomainOfAny(request.Req.Question[0].Name, r.cfg.AllowedDomains)
+}
+
+func answerIP(rr dns.RR) net.IP {
+	switch v := rr.(type) {
+	case *dns.A:
+		return v.A
+	case *dns.AAAA:
+		return v.AAAA
+	}
+
+	return nil
+}
+
+// isPrivateIP returns true for addresses which are not reachable from the internet:
+// RFC 1918, loopback, link-local, unique local (RFC 4193) and unspecified addresses
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsUnspecified()
+}
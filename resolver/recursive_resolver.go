@@ -0,0 +1,613 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/Abiji-2020/bGuard/cache/expirationcache"
+	"github.com/Abiji-2020/bGuard/config"
+	"github.com/Abiji-2020/bGuard/model"
+	"github.com/Abiji-2020/bGuard/util"
+
+	"github.com/miekg/dns"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	recursiveResolverType = "recursive"
+
+	recursiveUDPSize           = 1232
+	recursiveMaxServerAttempts = 4
+	recursiveMaxNSLookups      = 3
+	recursiveMaxMinimiseSteps  = 10
+	recursiveMaxDelegationTTL  = 24 * time.Hour
+)
+
+var (
+	errRecursionDepth     = errors.New("maximum recursion depth exceeded")
+	errTooManyReferrals   = errors.New("too many referrals")
+	errNoUsableNameserver = errors.New("no usable name server")
+)
+
+// https://www.iana.org/domains/root/servers
+//
+//nolint:gochecknoglobals
+var defaultRootHints = []string{
+	"198.41.0.4", "2001:503:ba3e::2:30", // a.root-servers.net
+	"170.247.170.2", "2801:1b8:10::b", // b.root-servers.net
+	"192.33.4.12", "2001:500:2::c", // c.root-servers.net
+	"199.7.91.13", "2001:500:2d::d", // d.root-servers.net
+	"192.203.230.10", "2001:500:a8::e", // e.root-servers.net
+	"192.5.5.241", "2001:500:2f::f", // f.root-servers.net
+	"192.112.36.4", "2001:500:12::d0d", // g.root-servers.net
+	"198.97.190.53", "2001:500:1::53", // h.root-servers.net
+	"192.36.148.17", "2001:7fe::53", // i.root-servers.net
+	"192.58.128.30", "2001:503:c27::2:30", // j.root-servers.net
+	"193.0.14.129", "2001:7fd::1", // k.root-servers.net
+	"199.7.83.42", "2001:500:9f::42", // l.root-servers.net
+	"202.12.27.33", "2001:dc3::35", // m.root-servers.net
+}
+
+// delegation is a zone cut and the addresses of its authoritative servers
+type delegation struct {
+	zone    string
+	servers []net.IP
+}
+
+// RecursiveResolver resolves queries iteratively, starting at the root servers and following delegations
+type RecursiveResolver struct {
+	configurable[*config.Recursive]
+	typed
+
+	timeout          time.Duration
+	connectIPVersion config.IPVersion
+	rootServers      []net.IP
+	delegations      expirationcache.ExpiringCache[delegation]
+	udpClient        *dns.Client
+	tcpClient        *dns.Client
+
+	// To allow replacing during tests
+	serverAddr func(ip net.IP) string
+}
+
+// NewRecursiveResolver creates a new recursive resolver instance
+func NewRecursiveResolver(
+	ctx context.Context, cfg config.Upstreams, bootstrap *Bootstrap,
+) (*RecursiveResolver, error) {
+	r := newRecursiveResolverUnchecked(ctx, cfg, bootstrap)
+
+	onErr := func(err error) {
+		_, logger := r.log(ctx)
+
+		logger.WithError(err).Warn("initial resolver test failed")
+	}
+
+	err := cfg.Init.Strategy.Do(ctx, r.testResolve, onErr)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func newRecursiveResolverUnchecked(ctx context.Context, cfg config.Upstreams, bootstrap *Bootstrap) *RecursiveResolver {
+	recursiveCfg := cfg.Recursive
+
+	connectIPVersion := config.IPVersionDual
+	if bootstrap != nil {
+		connectIPVersion = bootstrap.cfg.connectIPVersion
+	}
+
+	rootHints := recursiveCfg.RootHints
+	if len(rootHints) == 0 {
+		rootHints = make([]net.IP, 0, len(defaultRootHints))
+
+		for _, hint := range defaultRootHints {
+			rootHints = append(rootHints, net.ParseIP(hint))
+		}
+	}
+
+	r := &RecursiveResolver{
+		configurable: withConfig(&recursiveCfg),
+		typed:        withType(recursiveResolverType),
+
+		timeout:          cfg.Timeout.ToDuration(),
+		connectIPVersion: connectIPVersion,
+		delegations: expirationcache.NewCache[delegation](ctx, expirationcache.Options{
+			MaxSize: recursiveCfg.DelegationCacheSize,
+		}),
+		udpClient: &dns.Client{Net: "udp", UDPSize: recursiveUDPSize},
+		tcpClient: &dns.Client{Net: "tcp"},
+
+		serverAddr: func(ip net.IP) string {
+			return net.JoinHostPort(ip.String(), "53")
+		},
+	}
+
+	r.rootServers = r.filterIPVersion(rootHints)
+
+	return r
+}
+
+func (r *RecursiveResolver) String() string {
+	return r.Type()
+}
+
+// testResolve sends a test query to verify the root servers are reachable
+func (r *RecursiveResolver) testResolve(ctx context.Context) error {
+	// example.com MUST always resolve. See SUDN resolver
+	request := newRequest("example.com.", dns.Type(dns.TypeA))
+
+	_, err := r.Resolve(ctx, request)
+
+	return err
+}
+
+// Resolve resolves the request iteratively
+func (r *RecursiveResolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
+	ctx, logger := r.log(ctx)
+
+	question := request.Req.Question[0]
+	start := time.Now()
+
+	res, err := r.resolve(ctx, question.Name, question.Qtype, 0)
+	if err != nil {
+		return nil, fmt.Errorf("recursive resolution of %s failed: %w", util.Obfuscate(question.Name), err)
+	}
+
+	response := new(dns.Msg)
+	response.SetReply(request.Req)
+	response.Rcode = res.Rcode
+	response.Answer = res.Answer
+	response.Ns = res.Ns
+
+	logger.WithFields(logrus.Fields{
+		"answer":           util.AnswerToString(response.Answer),
+		"return_code":      dns.RcodeToString[response.Rcode],
+		"response_time_ms": time.Since(start).Milliseconds(),
+	}).Debug("resolved recursively")
+
+	return &model.Response{Res: response, Reason: "RESOLVED (recursive)"}, nil
+}
+
+// resolve follows delegations from the closest known zone cut down to the authoritative servers of qName
+//
+//nolint:funlen,gocognit
+func (r *RecursiveResolver) resolve(ctx context.Context, qName string, qType uint16, depth uint) (*dns.Msg, error) {
+	if depth > r.cfg.MaxDepth {
+		return nil, errRecursionDepth
+	}
+
+	_, logger := r.log(ctx)
+
+	qName = dns.CanonicalName(qName)
+	cut := r.closestDelegation(qName)
+
+	// known is the deepest name that is known to exist on the current servers
+	known := cut.zone
+	minimise := r.cfg.QNameMinimisation
+
+	var referrals, minimiseSteps uint
+
+	for referrals <= r.cfg.MaxReferrals {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		queryName, queryType := qName, qType
+
+		if minimise && minimiseSteps < recursiveMaxMinimiseSteps {
+			if name := minimisedName(known, qName); name != qName {
+				queryName, queryType = name, dns.TypeNS
+				minimiseSteps++
+			}
+		}
+
+		isMinimised := queryName != qName
+
+		resp, err := r.queryServers(ctx, cut, queryName, queryType)
+		if err != nil {
+			if isMinimised {
+				// Some servers mishandle minimised queries, retry with the full name
+				logger.WithField("zone", cut.zone).Debugf("minimised query failed, disabling minimisation: %s", err)
+
+				minimise = false
+
+				continue
+			}
+
+			return nil, err
+		}
+
+		scrubOutOfBailiwick(resp, cut.zone)
+
+		if resp.Rcode == dns.RcodeNameError {
+			// RFC 8020: nothing exists below a non-existent name
+			return resp, nil
+		}
+
+		if child, nsRecords := findReferral(resp, cut.zone, queryName); child != "" {
+			servers, err := r.delegationServers(ctx, nsRecords, resp.Extra, cut.zone, depth)
+			if err != nil {
+				return nil, fmt.Errorf("delegation to %s: %w", child, err)
+			}
+
+			logger.WithFields(logrus.Fields{
+				"zone":    child,
+				"servers": servers,
+			}).Trace("following referral")
+
+			next := delegation{zone: child, servers: servers}
+			r.delegations.Put(child, &next, delegationTTL(nsRecords))
+
+			cut = next
+			known = child
+			referrals++
+
+			continue
+		}
+
+		if isMinimised {
+			if hasRecordOfType(resp.Answer, queryName, dns.TypeCNAME) {
+				// An alias in the middle of the name: only the full query can be answered
+				minimise = false
+
+				continue
+			}
+
+			// The name exists, but there is no zone cut: go one label deeper
+			known = queryName
+
+			continue
+		}
+
+		return r.followCNAMEs(ctx, resp, qName, qType, depth)
+	}
+
+	return nil, errTooManyReferrals
+}
+
+// followCNAMEs resolves the target of a CNAME chain when the authoritative server only returned the alias
+func (r *RecursiveResolver) followCNAMEs(
+	ctx context.Context, resp *dns.Msg, qName string, qType uint16, depth uint,
+) (*dns.Msg, error) {
+	if qType == dns.TypeCNAME || qType == dns.TypeANY {
+		return resp, nil
+	}
+
+	target := qName
+
+	for seen := 0; seen < len(resp.Answer); seen++ {
+		next := ""
+
+		for _, rr := range resp.Answer {
+			if cname, ok := rr.(*dns.CNAME); ok && strings.EqualFold(cname.Hdr.Name, target) {
+				next = dns.CanonicalName(cname.Target)
+
+				break
+			}
+		}
+
+		if next == "" {
+			break
+		}
+
+		target = next
+	}
+
+	if target == qName || hasRecordOfType(resp.Answer, target, qType) {
+		return resp, nil
+	}
+
+	targetResp, err := r.resolve(ctx, target, qType, depth+1)
+	if err != nil {
+		return nil, fmt.Errorf("CNAME target %s: %w", target, err)
+	}
+
+	result := resp.Copy()
+	result.Rcode = targetResp.Rcode
+	result.Answer = append(result.Answer, targetResp.Answer...)
+	result.Ns = targetResp.Ns
+
+	return result, nil
+}
+
+// closestDelegation returns the deepest cached zone cut above qName, or the root
+func (r *RecursiveResolver) closestDelegation(qName string) delegation {
+	for name := qName; name != "."; name = parentName(name) {
+		if cut, _ := r.delegations.Get(name); cut != nil {
+			return *cut
+		}
+	}
+
+	return delegation{zone: ".", servers: r.rootServers}
+}
+
+// queryServers sends the query to the servers of cut until one of them gives a usable answer
+func (r *RecursiveResolver) queryServers(
+	ctx context.Context, cut delegation, qName string, qType uint16,
+) (*dns.Msg, error) {
+	_, logger := r.log(ctx)
+
+	servers := make([]net.IP, len(cut.servers))
+	copy(servers, cut.servers)
+	rand.Shuffle(len(servers), func(i, j int) { servers[i], servers[j] = servers[j], servers[i] }) //nolint:gosec
+
+	if len(servers) > recursiveMaxServerAttempts {
+		servers = servers[:recursiveMaxServerAttempts]
+	}
+
+	errs := make([]error, 0, len(servers))
+
+	for _, server := range servers {
+		resp, err := r.exchange(ctx, server, qName, qType)
+		if err == nil {
+			err = checkAuthoritativeResponse(resp, cut.zone, qName)
+		}
+
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"zone":   cut.zone,
+				"server": server,
+			}).Debugf("skipping name server: %s", err)
+
+			errs = append(errs, fmt.Errorf("%s: %w", server, err))
+
+			continue
+		}
+
+		return resp, nil
+	}
+
+	return nil, fmt.Errorf("%w for %s: %w", errNoUsableNameserver, cut.zone, errors.Join(errs...))
+}
+
+// exchange sends a single non-recursive query via UDP, and retries over TCP if the response was truncated
+func (r *RecursiveResolver) exchange(ctx context.Context, server net.IP, qName string, qType uint16) (*dns.Msg, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(qName, qType)
+	msg.RecursionDesired = false
+	msg.SetEdns0(recursiveUDPSize, false)
+
+	addr := r.serverAddr(server)
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	resp, _, err := r.udpClient.ExchangeContext(ctx, msg, addr)
+	if err == nil && resp.Truncated {
+		resp, _, err = r.tcpClient.ExchangeContext(ctx, msg, addr)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Question) != 1 || !strings.EqualFold(resp.Question[0].Name, qName) ||
+		resp.Question[0].Qtype != qType {
+		return nil, errors.New("response question does not match query")
+	}
+
+	return resp, nil
+}
+
+// checkAuthoritativeResponse detects lame servers: errors, and answers that neither answer nor refer downwards
+func checkAuthoritativeResponse(resp *dns.Msg, zone, qName string) error {
+	switch resp.Rcode {
+	case dns.RcodeSuccess, dns.RcodeNameError:
+	default:
+		return fmt.Errorf("lame server, return code %s", dns.RcodeToString[resp.Rcode])
+	}
+
+	if resp.Authoritative || len(resp.Answer) > 0 {
+		return nil
+	}
+
+	if child, _ := findReferral(resp, zone, qName); child != "" {
+		return nil
+	}
+
+	return errors.New("lame delegation, server is not authoritative")
+}
+
+// scrubOutOfBailiwick removes the answer and authority records outside of the zone of the answering servers:
+// they aren't authoritative for them, so the records could poison the caches
+func scrubOutOfBailiwick(resp *dns.Msg, zone string) {
+	resp.Answer = recordsInZone(resp.Answer, zone)
+	resp.Ns = recordsInZone(resp.Ns, zone)
+}
+
+func recordsInZone(records []dns.RR, zone string) []dns.RR {
+	result := make([]dns.RR, 0, len(records))
+
+	for _, rr := range records {
+		if dns.IsSubDomain(zone, dns.CanonicalName(rr.Header().Name)) {
+			result = append(result, rr)
+		}
+	}
+
+	return result
+}
+
+// findReferral returns the delegated zone and its NS records if resp refers to a zone below zone
+func findReferral(resp *dns.Msg, zone, qName string) (string, []*dns.NS) {
+	candidates := resp.Ns
+
+	if len(resp.Answer) > 0 {
+		// A server authoritative for both parent and child answers the minimised NS query directly
+		candidates = resp.Answer
+	}
+
+	var (
+		child   string
+		records []*dns.NS
+	)
+
+	for _, rr := range candidates {
+		ns, ok := rr.(*dns.NS)
+		if !ok {
+			continue
+		}
+
+		owner := dns.CanonicalName(ns.Hdr.Name)
+
+		// Only accept referrals strictly below the current zone, towards qName
+		if owner == zone || !dns.IsSubDomain(zone, owner) || !dns.IsSubDomain(owner, qName) {
+			continue
+		}
+
+		if len(resp.Answer) > 0 && owner != qName {
+			continue
+		}
+
+		if child != "" && owner != child {
+			continue
+		}
+
+		child = owner
+		records = append(records, ns)
+	}
+
+	return child, records
+}
+
+// delegationServers returns the addresses of the delegated name servers,
+// from in-bailiwick glue if possible, or by resolving the name server names
+func (r *RecursiveResolver) delegationServers(
+	ctx context.Context, nsRecords []*dns.NS, extra []dns.RR, parentZone string, depth uint,
+) ([]net.IP, error) {
+	var servers []net.IP
+
+	for _, ns := range nsRecords {
+		target := dns.CanonicalName(ns.Ns)
+
+		// Glue outside of the parent zone can't be trusted
+		if !dns.IsSubDomain(parentZone, target) {
+			continue
+		}
+
+		for _, rr := range extra {
+			if !strings.EqualFold(rr.Header().Name, target) {
+				continue
+			}
+
+			switch v := rr.(type) {
+			case *dns.A:
+				servers = append(servers, v.A)
+			case *dns.AAAA:
+				servers = append(servers, v.AAAA)
+			}
+		}
+	}
+
+	servers = r.filterIPVersion(servers)
+	if len(servers) > 0 {
+		return servers, nil
+	}
+
+	errs := make([]error, 0, recursiveMaxNSLookups)
+
+	for i, ns := range nsRecords {
+		if i >= recursiveMaxNSLookups {
+			break
+		}
+
+		for _, qType := range r.connectIPVersion.QTypes() {
+			resp, err := r.resolve(ctx, ns.Ns, uint16(qType), depth+1)
+			if err != nil {
+				errs = append(errs, err)
+
+				continue
+			}
+
+			servers = append(servers, extractIPs(resp.Answer)...)
+		}
+
+		if len(servers) > 0 {
+			return servers, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: can't resolve any name server address: %w", errNoUsableNameserver, errors.Join(errs...))
+}
+
+func (r *RecursiveResolver) filterIPVersion(ips []net.IP) []net.IP {
+	result := make([]net.IP, 0, len(ips))
+
+	for _, ip := range ips {
+		isV4 := ip.To4() != nil
+
+		switch {
+		case r.connectIPVersion == config.IPVersionV4 && !isV4:
+		case r.connectIPVersion == config.IPVersionV6 && isV4:
+		default:
+			result = append(result, ip)
+		}
+	}
+
+	return result
+}
+
+func extractIPs(answer []dns.RR) []net.IP {
+	ips := make([]net.IP, 0, len(answer))
+
+	for _, rr := range answer {
+		switch v := rr.(type) {
+		case *dns.A:
+			ips = append(ips, v.A)
+		case *dns.AAAA:
+			ips = append(ips, v.AAAA)
+		}
+	}
+
+	return ips
+}
+
+func hasRecordOfType(rrs []dns.RR, name string, qType uint16) bool {
+	for _, rr := range rrs {
+		if rr.Header().Rrtype == qType && strings.EqualFold(rr.Header().Name, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func delegationTTL(nsRecords []*dns.NS) time.Duration {
+	ttl := recursiveMaxDelegationTTL
+
+	for _, ns := range nsRecords {
+		if nsTTL := time.Duration(ns.Hdr.Ttl) * time.Second; nsTTL < ttl {
+			ttl = nsTTL
+		}
+	}
+
+	return ttl
+}
+
+// minimisedName returns the ancestor of qName that has exactly one label more than known (RFC 9156)
+func minimisedName(known, qName string) string {
+	knownLabels := dns.CountLabel(known)
+	labels := dns.SplitDomainName(qName)
+
+	if knownLabels+1 >= len(labels) {
+		return qName
+	}
+
+	return dns.Fqdn(strings.Join(labels[len(labels)-knownLabels-1:], "."))
+}
+
+// parentName returns the name without its first label, "." for a TLD
+func parentName(name string) string {
+	_, parent, _ := strings.Cut(name, ".")
+	if parent == "" {
+		return "."
+	}
+
+	return parent
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strings"
 	"time"
 
 	"github.com/Abiji-2020/bGuard/config"
@@ -268,7 +269,17 @@ func createGroupResolvers(
 	resolvers := make([]*upstreamResolverStatus, 0, len(upstreams))
 
 	for _, upstream := range upstreams {
-		resolver, err := NewUpstreamResolver(ctx, newUpstreamConfig(upstream, cfg.Upstreams), bootstrap)
+		var (
+			resolver Resolver
+			err      error
+		)
+
+		if upstream.IsRecursive() {
+			resolver, err = NewRecursiveResolver(ctx, cfg.Upstreams, bootstrap)
+		} else {
+			resolver, err = NewUpstreamResolver(ctx, newUpstreamConfig(upstream, cfg.Upstreams), bootstrap)
+		}
+
 		if err != nil {
 			continue // err was already logged
 		}
@@ -282,3 +293,18 @@ func createGroupResolvers(
 
 	return resolvers, nil
 }
+
+// isSubDomainOfAny checks if name is one of the domains or a sub-domain of one of them
+func isSubDomainOfAny(name string, domains []string) bool {
+	domain := util.ExtractDomainOnly(name)
+
+	for _, parent := range domains {
+		parent = util.ExtractDomainOnly(parent)
+
+		if domain == parent || strings.HasSuffix(domain, "."+parent) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,280 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sync/atomic"
+
+	"github.com/Abiji-2020/bGuard/config"
+	"github.com/Abiji-2020/bGuard/lists"
+	"github.com/Abiji-2020/bGuard/lists/parsers"
+	"github.com/Abiji-2020/bGuard/model"
+	"github.com/Abiji-2020/bGuard/safesearch"
+	"github.com/Abiji-2020/bGuard/util"
+
+	"github.com/miekg/dns"
+	"github.com/sirupsen/logrus"
+)
+
+// SafeSearchResolver enforces the safe search and restricted modes of search engines
+// by rewriting their domains to the safe endpoints of the mapping
+type SafeSearchResolver struct {
+	configurable[*config.SafeSearch]
+	NextResolver
+	typed
+
+	profiles   config.Profiles
+	clients    *clientMatcher[bool]
+	downloader lists.FileDownloader
+	// rewrites by domain
+	rewrites atomic.Pointer[map[string]safeSearchRewrite]
+}
+
+type safeSearchRewrite struct {
+	engine   string
+	endpoint safesearch.Endpoint
+}
+
+// NewSafeSearchResolver creates new resolver instance, profiles may enable or disable it per client
+func NewSafeSearchResolver(ctx context.Context,
+	cfg config.SafeSearch,
+	profiles config.Profiles,
+	bootstrap *Bootstrap,
+) (*SafeSearchResolver, error) {
+	r := &SafeSearchResolver{
+		configurable: withConfig(&cfg),
+		typed:        withType("safe_search"),
+
+		profiles:   profiles,
+		clients:    newClientMatcher[bool](),
+		downloader: lists.NewDownloader(cfg.Loading.Downloads, bootstrap.NewHTTPTransport()),
+	}
+
+	for _, client := range cfg.Clients {
+		r.clients.add(client, true)
+	}
+
+	r.rewrites.Store(&map[string]safeSearchRewrite{})
+
+	if !r.isUsed() {
+		return r, nil
+	}
+
+	if len(cfg.Sources) == 0 {
+		err := r.loadMapping(ctx, func(ctx context.Context, entries *[]safesearch.Entry) error {
+			return r.parseMapping(ctx, "embedded mapping", safesearch.DefaultMapping(), entries)
+		})
+
+		return r, err
+	}
+
+	err := cfg.Loading.StartPeriodicRefresh(ctx, r.loadSources, func(err error) {
+		_, logger := r.log(ctx)
+		logger.WithError(err).Errorf("could not load safe search mapping")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// isUsed returns true if the resolver is enabled for some clients or profiles
+func (r *SafeSearchResolver) isUsed() bool {
+	if r.IsEnabled() {
+		return true
+	}
+
+	for _, profile := range r.profiles {
+		if profile.SafeSearch != nil && *profile.SafeSearch {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *SafeSearchResolver) isEnabledFor(request *model.Request) bool {
+	if profile, ok := r.profiles[request.Profile]; ok && profile.SafeSearch != nil {
+		return *profile.SafeSearch
+	}
+
+	_, ok := r.clients.match(request)
+
+	return ok
+}
+
+// LogConfig implements `config.Configurable`.
+func (r *SafeSearchResolver) LogConfig(logger *logrus.Entry) {
+	r.cfg.LogConfig(logger)
+
+	logger.Infof("rewritten domains = %d", len(*r.rewrites.Load()))
+}
+
+func (r *SafeSearchResolver) loadSources(ctx context.Context) error {
+	return r.loadMapping(ctx, func(ctx context.Context, entries *[]safesearch.Entry) error {
+		for i, source := range r.cfg.Sources {
+			opener, err := lists.NewSourceOpener(fmt.Sprintf("item #%d", i), source, r.downloader)
+			if err != nil {
+				return err
+			}
+
+			reader, err := opener.Open(ctx)
+			if err != nil {
+				return err
+			}
+
+			err = r.parseMapping(ctx, opener.String(), reader, entries)
+
+			reader.Close()
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// loadMapping replaces the rewrites by the entries of the enabled engines
+func (r *SafeSearchResolver) loadMapping(ctx context.Context,
+	read func(context.Context, *[]safesearch.Entry) error,
+) error {
+	var entries []safesearch.Entry
+
+	if err := read(ctx, &entries); err != nil {
+		return err
+	}
+
+	rewrites := make(map[string]safeSearchRewrite)
+
+	// engines are applied in reverse order, so the first engine wins
+	for i := len(r.cfg.Engines) - 1; i >= 0; i-- {
+		engine := r.cfg.Engines[i]
+
+		for _, entry := range entries {
+			if entry.Engine != engine {
+				continue
+			}
+
+			for _, domain := range entry.Domains {
+				rewrites[domain] = safeSearchRewrite{engine: engine, endpoint: entry.Endpoint}
+			}
+		}
+	}
+
+	for _, engine := range r.cfg.Engines {
+		if !slices.ContainsFunc(entries, func(entry safesearch.Entry) bool { return entry.Engine == engine }) {
+			_, logger := r.log(ctx)
+			logger.Warnf("safe search engine '%s' is not in the mapping", engine)
+		}
+	}
+
+	r.rewrites.Store(&rewrites)
+
+	return nil
+}
+
+func (r *SafeSearchResolver) parseMapping(ctx context.Context, name string, reader io.Reader,
+	entries *[]safesearch.Entry,
+) error {
+	p := parsers.AllowErrors(parsers.LinesAs[*safesearch.Entry](reader), r.cfg.Loading.MaxErrorsPerSource)
+	p.OnErr(func(err error) {
+		_, logger := r.log(ctx)
+
+		logger.Warnf("error parsing %s: %s, trying to continue", name, err)
+	})
+
+	err := parsers.ForEach[*safesearch.Entry](ctx, p, func(entry *safesearch.Entry) error {
+		*entries = append(*entries, *entry)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// Resolve rewrites the domains of search engines for the clients with enforced safe search
+func (r *SafeSearchResolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
+	if !r.isEnabledFor(request) {
+		return r.next.Resolve(ctx, request)
+	}
+
+	question := request.Req.Question[0]
+	domain := util.ExtractDomain(question)
+
+	rewrite, ok := (*r.rewrites.Load())[domain]
+	if !ok {
+		return r.next.Resolve(ctx, request)
+	}
+
+	ctx, logger := r.log(ctx)
+
+	response := new(dns.Msg)
+	response.SetReply(request.Req)
+
+	answer, err := r.answer(ctx, request, question, rewrite.endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	response.Answer = answer
+
+	logger.WithFields(logrus.Fields{
+		"answer": util.AnswerToString(response.Answer),
+		"domain": util.Obfuscate(domain),
+	}).Debugf("enforcing %s safe search", rewrite.engine)
+
+	return &model.Response{
+		Res:    response,
+		RType:  model.ResponseTypeSAFESEARCH,
+		Reason: fmt.Sprintf("SAFE SEARCH (%s)", rewrite.engine),
+	}, nil
+}
+
+// answer returns a CNAME to the endpoint host with its records, or the A/AAAA records of the endpoint IPs
+func (r *SafeSearchResolver) answer(ctx context.Context, request *model.Request, question dns.Question,
+	endpoint safesearch.Endpoint,
+) ([]dns.RR, error) {
+	ttl := r.cfg.TTL.SecondsU32()
+
+	if endpoint.Host == "" {
+		var result []dns.RR
+
+		for _, ip := range endpoint.IPs {
+			if !isSupportedType(ip, question) {
+				continue
+			}
+
+			rr, err := util.CreateAnswerFromQuestion(question, ip, ttl)
+			if err != nil {
+				return nil, err
+			}
+
+			result = append(result, rr)
+		}
+
+		return result, nil
+	}
+
+	cname := new(dns.CNAME)
+	cname.Hdr = dns.RR_Header{Class: dns.ClassINET, Ttl: ttl, Rrtype: dns.TypeCNAME, Name: question.Name}
+	cname.Target = dns.Fqdn(endpoint.Host)
+
+	if question.Qtype == dns.TypeCNAME {
+		return []dns.RR{cname}, nil
+	}
+
+	targetResp, err := r.next.Resolve(ctx, subRequest(request, endpoint.Host, question.Qtype))
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]dns.RR{cname}, targetResp.Res.Answer...), nil
+}
@@ -0,0 +1,103 @@
+package resolver
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net"
+	"slices"
+	"sync"
+
+	"github.com/Abiji-2020/bGuard/util"
+	"github.com/miekg/dns"
+)
+
+const (
+	upstreamClientCookieLen = 8
+	upstreamCookieSecretLen = 16
+	// the highest RCODE without extension by the OPT record
+	maxHeaderRcode = 0xF
+)
+
+var errCookieMismatch = errors.New("response client cookie doesn't match the request")
+
+// upstreamCookies sends DNS client cookies (RFC 7873) to an upstream and remembers the server cookies it returns
+type upstreamCookies struct {
+	// the client cookie of each server is derived from its IP, so servers can't correlate the client (RFC 9018)
+	secret []byte
+
+	lock          sync.RWMutex
+	serverCookies map[string][]byte // by upstream IP
+}
+
+func newUpstreamCookies() *upstreamCookies {
+	secret := make([]byte, upstreamCookieSecretLen)
+
+	_, err := rand.Read(secret)
+	util.FatalOnError("can't create DNS client cookie secret: ", err)
+
+	return &upstreamCookies{
+		secret:        secret,
+		serverCookies: make(map[string][]byte),
+	}
+}
+
+// clientCookie returns the client cookie for the upstream with the given IP
+func (c *upstreamCookies) clientCookie(ip net.IP) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(ip.To16())
+
+	return mac.Sum(nil)[:upstreamClientCookieLen]
+}
+
+// addToRequest returns a copy of msg with the COOKIE option for the upstream with the given IP
+func (c *upstreamCookies) addToRequest(msg *dns.Msg, ip net.IP) *dns.Msg {
+	c.lock.RLock()
+	cookie := slices.Concat(c.clientCookie(ip), c.serverCookies[ip.String()])
+	c.lock.RUnlock()
+
+	msg = msg.Copy()
+
+	if msg.IsEdns0() == nil {
+		msg.SetEdns0(dns.DefaultMsgSize, false)
+	}
+
+	util.SetEdns0Option(msg, &dns.EDNS0_COOKIE{
+		Code:   dns.EDNS0COOKIE,
+		Cookie: hex.EncodeToString(cookie),
+	})
+
+	return msg
+}
+
+// handleResponse verifies the client cookie echoed by the upstream, stores its server cookie
+// and removes the COOKIE option from the response
+func (c *upstreamCookies) handleResponse(request, response *dns.Msg, ip net.IP) error {
+	if request.IsEdns0() == nil && response.Rcode <= maxHeaderRcode {
+		// the OPT record was only added for the cookie, it is kept for extended RCODEs like BADCOOKIE
+		defer util.RemoveEdns0Record(response)
+	}
+
+	opt := util.GetEdns0Option[*dns.EDNS0_COOKIE](response)
+	if opt == nil {
+		// the upstream doesn't support cookies
+		return nil
+	}
+
+	cookie, err := hex.DecodeString(opt.Cookie)
+	if err != nil || len(cookie) < upstreamClientCookieLen ||
+		!bytes.Equal(cookie[:upstreamClientCookieLen], c.clientCookie(ip)) {
+		return errCookieMismatch
+	}
+
+	c.lock.Lock()
+	c.serverCookies[ip.String()] = cookie[upstreamClientCookieLen:]
+	c.lock.Unlock()
+
+	util.StripEdns0Option[*dns.EDNS0_COOKIE](response)
+
+	return nil
+}
@@ -3,6 +3,7 @@ package resolver
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"crypto/tls"
 	"errors"
 	"fmt"
@@ -28,6 +29,8 @@ const (
 	retryAttempts  = 3
 )
 
+var errCaseMismatch = errors.New("response query name doesn't match the randomized case")
+
 // UpstreamServerError wraps a response with RCode ServFail so no other resolver tries to use it.
 type UpstreamServerError struct {
 	Msg *dns.Msg
@@ -67,6 +70,7 @@ type UpstreamResolver struct {
 
 	upstreamClient upstreamClient
 	bootstrap      *Bootstrap
+	cookies        *upstreamCookies
 }
 
 type upstreamClient interface {
@@ -78,6 +82,9 @@ type upstreamClient interface {
 
 type dnsUpstreamClient struct {
 	tcpClient, udpClient *dns.Client
+
+	// randomize the case of the query name sent over UDP (0x20 encoding)
+	caseRandomization bool
 }
 
 type httpUpstreamClient struct {
@@ -125,6 +132,7 @@ func createUpstreamClient(cfg upstreamConfig) upstreamClient {
 			udpClient: &dns.Client{
 				Net: "udp",
 			},
+			caseRandomization: cfg.CaseRandomization,
 		}
 
 	default:
@@ -221,18 +229,31 @@ func (r *dnsUpstreamClient) raceClients(
 	// it will be GC'ed and closed automatically.
 	ch := make(chan exchangeResult, 2) //nolint:mnd // TCP and UDP
 
-	exchange := func(client *dns.Client, proto model.RequestProtocol) {
-		msg, rtt, err := client.ExchangeContext(ctx, msg, upstreamURL)
+	udpMsg := msg
+
+	if r.caseRandomization && len(msg.Question) == 1 {
+		udpMsg = msg.Copy()
+		udpMsg.Question[0].Name = randomizeCase(msg.Question[0].Name)
+	}
+
+	exchange := func(client *dns.Client, proto model.RequestProtocol, query *dns.Msg) {
+		res, rtt, err := client.ExchangeContext(ctx, query, upstreamURL)
+
+		if err == nil && query != msg {
+			// A mismatch means the response is spoofed, or the upstream doesn't preserve the case:
+			// either way the TCP response is used instead.
+			err = restoreCase(res, query.Question[0].Name, msg.Question[0].Name)
+		}
 
-		if err == nil && msg.Rcode == dns.RcodeServerFailure {
-			err = &UpstreamServerError{msg}
+		if err == nil && res.Rcode == dns.RcodeServerFailure {
+			err = &UpstreamServerError{res}
 		}
 
-		ch <- exchangeResult{proto, msg, rtt, err}
+		ch <- exchangeResult{proto, res, rtt, err}
 	}
 
-	go exchange(r.tcpClient, model.RequestProtocolTCP)
-	go exchange(r.udpClient, model.RequestProtocolUDP)
+	go exchange(r.tcpClient, model.RequestProtocolTCP, msg)
+	go exchange(r.udpClient, model.RequestProtocolUDP, udpMsg)
 
 	// We don't care about a response too big for the downstream protocol: that's handled by `Server`,
 	// and returning a larger request from here might allow us to cache it.
@@ -268,10 +289,55 @@ func (r *dnsUpstreamClient) raceClients(
 	return successful.msg, successful.rtt, nil
 }
 
+// randomizeCase flips the case of each letter in name with a probability of 1/2 (draft-vixie-dnsext-dns0x20)
+func randomizeCase(name string) string {
+	bits := make([]byte, (len(name)+7)/8) //nolint:mnd // one bit per character
+
+	if _, err := rand.Read(bits); err != nil {
+		return name
+	}
+
+	result := []byte(name)
+
+	for i, c := range result {
+		isLetter := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+
+		if isLetter && bits[i/8]&(1<<(i%8)) != 0 {
+			result[i] ^= 0x20 // ASCII case bit
+		}
+	}
+
+	return string(result)
+}
+
+// restoreCase checks that the response echoes the query name with the exact same case
+// and restores the original name
+func restoreCase(response *dns.Msg, sentName, originalName string) error {
+	if len(response.Question) != 1 || response.Question[0].Name != sentName {
+		return errCaseMismatch
+	}
+
+	response.Question[0].Name = originalName
+
+	for _, section := range [][]dns.RR{response.Answer, response.Ns, response.Extra} {
+		for _, rr := range section {
+			if rr.Header().Name == sentName {
+				rr.Header().Name = originalName
+			}
+		}
+	}
+
+	return nil
+}
+
 // NewUpstreamResolver creates new resolver instance
 func NewUpstreamResolver(
 	ctx context.Context, cfg upstreamConfig, bootstrap *Bootstrap,
 ) (*UpstreamResolver, error) {
+	if cfg.IsRecursive() {
+		return nil, fmt.Errorf("%s can only be used in upstream groups and conditional mappings", cfg.Upstream)
+	}
+
 	r := newUpstreamResolverUnchecked(cfg, bootstrap)
 
 	onErr := func(err error) {
@@ -292,13 +358,20 @@ func NewUpstreamResolver(
 func newUpstreamResolverUnchecked(cfg upstreamConfig, bootstrap *Bootstrap) *UpstreamResolver {
 	upstreamClient := createUpstreamClient(cfg)
 
-	return &UpstreamResolver{
+	r := UpstreamResolver{
 		typed:        withType("upstream"),
 		configurable: withConfig(cfg),
 
 		upstreamClient: upstreamClient,
 		bootstrap:      bootstrap,
 	}
+
+	// DoH is already protected against spoofing by TLS
+	if cfg.Cookies && cfg.Net != config.NetProtocolHttps {
+		r.cookies = newUpstreamCookies()
+	}
+
+	return &r
 }
 
 func (r UpstreamResolver) String() string {
@@ -347,7 +420,7 @@ func (r *UpstreamResolver) Resolve(ctx context.Context, request *model.Request)
 			ctx, cancel := context.WithTimeout(ctx, r.cfg.Timeout.ToDuration())
 			defer cancel()
 
-			response, rtt, err := r.upstreamClient.callExternal(ctx, request.Req, upstreamURL, request.Protocol)
+			response, rtt, err := r.exchange(ctx, request, upstreamURL, ip)
 			if err != nil {
 				return fmt.Errorf("can't resolve request via upstream server %s (%s): %w", r.cfg, upstreamURL, err)
 			}
@@ -380,6 +453,50 @@ func (r *UpstreamResolver) Resolve(ctx context.Context, request *model.Request)
 	return &model.Response{Res: resp, Reason: fmt.Sprintf("RESOLVED (%s)", r.cfg)}, nil
 }
 
+// exchange sends the request to the upstream, with a DNS cookie if enabled
+func (r *UpstreamResolver) exchange(
+	ctx context.Context, request *model.Request, upstreamURL string, ip net.IP,
+) (*dns.Msg, time.Duration, error) {
+	if r.cookies == nil {
+		return r.upstreamClient.callExternal(ctx, request.Req, upstreamURL, request.Protocol)
+	}
+
+	for retried := false; ; retried = true {
+		msg := r.cookies.addToRequest(request.Req, ip)
+
+		response, rtt, err := r.upstreamClient.callExternal(ctx, msg, upstreamURL, request.Protocol)
+		if err != nil {
+			return response, rtt, err
+		}
+
+		err = r.cookies.handleResponse(request.Req, response, ip)
+		if err != nil {
+			return nil, rtt, err
+		}
+
+		if response.Rcode != dns.RcodeBadCookie {
+			return response, rtt, nil
+		}
+
+		if retried {
+			// the client doesn't know about the cookie of the upstream
+			response.Rcode = dns.RcodeServerFailure
+
+			if opt := response.IsEdns0(); opt != nil {
+				opt.SetExtendedRcode(dns.RcodeServerFailure)
+			}
+
+			if request.Req.IsEdns0() == nil {
+				util.RemoveEdns0Record(response)
+			}
+
+			return response, rtt, nil
+		}
+
+		// the BADCOOKIE response contains a fresh server cookie: retry once with it (RFC 7873 section 5.3)
+	}
+}
+
 func (r *UpstreamResolver) logResponse(
 	logger *logrus.Entry, request *model.Request, resp *dns.Msg, ip net.IP, rtt time.Duration,
 ) {
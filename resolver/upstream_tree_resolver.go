@@ -21,9 +21,12 @@ type UpstreamTreeResolver struct {
 	typed
 
 	branches map[string]Resolver
+	profiles config.Profiles
 }
 
-func NewUpstreamTreeResolver(ctx context.Context, cfg config.Upstreams, bootstrap *Bootstrap) (Resolver, error) {
+func NewUpstreamTreeResolver(
+	ctx context.Context, cfg config.Upstreams, profiles config.Profiles, bootstrap *Bootstrap,
+) (Resolver, error) {
 	if len(cfg.Groups[upstreamDefaultCfgName]) == 0 {
 		return nil, fmt.Errorf("no external DNS resolvers configured as default upstream resolvers. "+
 			"Please configure at least one under '%s' configuration name", upstreamDefaultCfgName)
@@ -46,6 +49,7 @@ func NewUpstreamTreeResolver(ctx context.Context, cfg config.Upstreams, bootstra
 		typed:        withType(upstreamTreeResolverType),
 
 		branches: branches,
+		profiles: profiles,
 	}
 
 	return &r, nil
@@ -119,6 +123,13 @@ func (r *UpstreamTreeResolver) upstreamGroupByClient(logger *logrus.Entry, reque
 	groups := make([]string, 0, len(r.branches))
 	clientIP := request.ClientIP.String()
 
+	// the profile replaces the group by client
+	if profile, ok := r.profiles[request.Profile]; ok && profile.UpstreamGroup != "" {
+		if _, exists := r.branches[profile.UpstreamGroup]; exists {
+			return profile.UpstreamGroup
+		}
+	}
+
 	// try IP
 	if _, exists := r.branches[clientIP]; exists {
 		return clientIP
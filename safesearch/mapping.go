@@ -0,0 +1,111 @@
+// Package safesearch maps the domains of search engines to their enforced safe search endpoints.
+package safesearch
+
+import (
+	_ "embed"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+//go:embed mapping.txt
+var defaultMapping string
+
+// DefaultMapping returns the embedded mapping
+func DefaultMapping() io.Reader {
+	return strings.NewReader(defaultMapping)
+}
+
+// Entry is a line of a mapping: the safe endpoint of an engine and the domains rewritten to it
+type Entry struct {
+	Engine   string
+	Endpoint Endpoint
+	Domains  []string
+}
+
+// UnmarshalText implements `encoding.TextUnmarshaler`.
+func (e *Entry) UnmarshalText(data []byte) error {
+	fields := strings.Fields(string(data))
+
+	const minFields = 3
+	if len(fields) < minFields {
+		return fmt.Errorf("expected '<engine> <endpoint> <domain>...', got '%s'", string(data))
+	}
+
+	var endpoint Endpoint
+	if err := endpoint.UnmarshalText([]byte(fields[1])); err != nil {
+		return err
+	}
+
+	domains := make([]string, 0, len(fields)-2) //nolint:mnd
+
+	for _, domain := range fields[2:] {
+		domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+
+		if _, ok := dns.IsDomainName(domain); !ok {
+			return fmt.Errorf("invalid domain '%s'", domain)
+		}
+
+		domains = append(domains, domain)
+	}
+
+	*e = Entry{Engine: strings.ToLower(fields[0]), Endpoint: endpoint, Domains: domains}
+
+	return nil
+}
+
+// Endpoint is the safe endpoint of an engine: a host name or IP addresses
+type Endpoint struct {
+	Host string
+	IPs  []net.IP
+}
+
+// UnmarshalText implements `encoding.TextUnmarshaler`.
+func (e *Endpoint) UnmarshalText(data []byte) error {
+	text := strings.TrimSpace(string(data))
+
+	if ip := net.ParseIP(strings.Split(text, ",")[0]); ip == nil {
+		host := strings.ToLower(strings.TrimSuffix(text, "."))
+
+		if _, ok := dns.IsDomainName(host); !ok {
+			return fmt.Errorf("invalid endpoint '%s'", text)
+		}
+
+		*e = Endpoint{Host: host}
+
+		return nil
+	}
+
+	var ips []net.IP
+
+	for _, part := range strings.Split(text, ",") {
+		ip := net.ParseIP(part)
+		if ip == nil {
+			return fmt.Errorf("invalid endpoint IP '%s'", part)
+		}
+
+		ips = append(ips, ip)
+	}
+
+	*e = Endpoint{IPs: ips}
+
+	return nil
+}
+
+// String implements `fmt.Stringer`.
+func (e Endpoint) String() string {
+	if e.Host != "" {
+		return e.Host
+	}
+
+	ips := make([]string, 0, len(e.IPs))
+
+	for _, ip := range e.IPs {
+		ips = append(ips, ip.String())
+	}
+
+	return strings.Join(ips, ",")
+}
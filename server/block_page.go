@@ -0,0 +1,116 @@
+package server
+
+import (
+	"html/template"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/Abiji-2020/bGuard/log"
+	"github.com/Abiji-2020/bGuard/resolver"
+	"github.com/Abiji-2020/bGuard/util"
+	"github.com/Abiji-2020/bGuard/web"
+)
+
+const unblockRequestPath = "/bguard/unblock-request"
+
+var blockPageTmpl = template.Must(template.New("blockpage").Funcs(template.FuncMap{
+	"join": strings.Join,
+}).Parse(web.BlockPageTmpl))
+
+// withBlockPage serves the block page for the domains, which were recently blocked for the client,
+// all other requests are passed to next
+func (s *Server) withBlockPage(next http.Handler) http.Handler {
+	if s.blockPage == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		clientIP := s.blockPageClientIP(req)
+
+		query, ok := s.blockPage.BlockedQuery(clientIP, hostWithoutPort(req.Host))
+		if !ok {
+			next.ServeHTTP(rw, req)
+
+			return
+		}
+
+		var requested bool
+
+		if req.Method == http.MethodPost && req.URL.Path == unblockRequestPath {
+			err := s.blockPage.RequestUnblock(req.Context(), clientIP, query.Domain, req.PostFormValue("comment"))
+			if err != nil {
+				http.Error(rw, err.Error(), http.StatusBadRequest)
+
+				return
+			}
+
+			requested = true
+		}
+
+		s.writeBlockPage(rw, query, requested)
+	})
+}
+
+// blockPageClientIP returns the IP of the client, the X-Forwarded-For header is only used for trusted proxies
+func (s *Server) blockPageClientIP(req *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+
+	remoteIP := net.ParseIP(host)
+
+	forwarded := req.Header.Values("X-Forwarded-For")
+	if len(forwarded) == 0 || !s.cfg.Blocking.BlockPage.IsTrustedProxy(remoteIP) {
+		return remoteIP
+	}
+
+	// the last address was added by the trusted proxy, the ones before it can be set by the client
+	addrs := strings.Split(forwarded[len(forwarded)-1], ",")
+
+	if ip := net.ParseIP(strings.TrimSpace(addrs[len(addrs)-1])); ip != nil {
+		return ip
+	}
+
+	return remoteIP
+}
+
+func (s *Server) writeBlockPage(rw http.ResponseWriter, query resolver.BlockedQuery, requested bool) {
+	type PageData struct {
+		Domain           string
+		Groups           []string
+		Reason           string
+		Requested        bool
+		UnblockRequests  bool
+		UnblockPath      string
+		MaxCommentLength int
+		Version          string
+	}
+
+	rw.Header().Set(contentTypeHeader, htmlContentType)
+	rw.Header().Set("cache-control", "no-store")
+	rw.WriteHeader(http.StatusForbidden)
+
+	err := blockPageTmpl.Execute(rw, PageData{
+		Domain:           query.Domain,
+		Groups:           query.Groups,
+		Reason:           query.Reason,
+		Requested:        requested,
+		UnblockRequests:  s.blockPage.UnblockRequestsEnabled(),
+		UnblockPath:      unblockRequestPath,
+		MaxCommentLength: resolver.MaxUnblockCommentLength,
+		Version:          util.Version,
+	})
+	if err != nil {
+		log.Log().Error("can't write block page template: ", log.EscapeInput(err.Error()))
+	}
+}
+
+func hostWithoutPort(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+
+	return host
+}
@@ -0,0 +1,250 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Abiji-2020/bGuard/cache/expirationcache"
+	"github.com/Abiji-2020/bGuard/config"
+	"github.com/Abiji-2020/bGuard/model"
+	"github.com/Abiji-2020/bGuard/util"
+	"github.com/dchest/siphash"
+	"github.com/miekg/dns"
+	"golang.org/x/time/rate"
+)
+
+// https://www.rfc-editor.org/rfc/rfc7873.html#section-4
+// https://www.rfc-editor.org/rfc/rfc9018.html#section-4
+const (
+	clientCookieLen    = 8
+	serverCookieMinLen = 8
+	serverCookieMaxLen = 32
+	serverCookieLen    = 16
+
+	serverCookieVersion = 1
+
+	// a cookie is valid for one hour and may be at most five minutes in the future
+	serverCookieMaxAge    = time.Hour
+	serverCookieMaxFuture = 5 * time.Minute
+
+	cookieSecretLen = 16
+
+	rateLimiterTTL     = time.Minute
+	rateLimiterMaxSize = 10000
+)
+
+// dnsCookies generates and validates server cookies and applies the rate limits for clients
+type dnsCookies struct {
+	cfg config.DNSCookies
+
+	lock     sync.RWMutex
+	current  [cookieSecretLen]byte
+	previous [cookieSecretLen]byte
+
+	limiters *expirationcache.ExpiringLRUCache[rate.Limiter]
+}
+
+func newDNSCookies(ctx context.Context, cfg config.DNSCookies) *dnsCookies {
+	c := &dnsCookies{
+		cfg: cfg,
+		limiters: expirationcache.NewCache[rate.Limiter](ctx, expirationcache.Options{
+			MaxSize: rateLimiterMaxSize,
+		}),
+	}
+
+	c.rotateSecret()
+	// cookies issued before the first rotation must not be accepted after it
+	c.previous = c.current
+
+	go func() {
+		ticker := time.NewTicker(cfg.SecretRotation.ToDuration())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.rotateSecret()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return c
+}
+
+func (c *dnsCookies) rotateSecret() {
+	var secret [cookieSecretLen]byte
+
+	_, err := rand.Read(secret[:])
+	util.FatalOnError("can't create DNS cookie secret: ", err)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.previous = c.current
+	c.current = secret
+}
+
+// check validates the COOKIE option of the request and applies the rate limits.
+// The option is removed from the request so it isn't forwarded to upstreams.
+// If a response is returned, it must be sent instead of resolving the request.
+func (c *dnsCookies) check(request *model.Request) (clientCookie []byte, response *dns.Msg) {
+	valid := false
+
+	if opt := util.GetEdns0Option[*dns.EDNS0_COOKIE](request.Req); opt != nil {
+		cookie, err := hex.DecodeString(opt.Cookie)
+		if err != nil || !isValidCookieLen(len(cookie)) {
+			response = new(dns.Msg)
+			response.SetRcode(request.Req, dns.RcodeFormatError)
+
+			return nil, response
+		}
+
+		clientCookie = cookie[:clientCookieLen]
+		valid = c.isValidServerCookie(cookie, request.ClientIP)
+
+		util.StripEdns0Option[*dns.EDNS0_COOKIE](request.Req)
+	}
+
+	// TCP already validates the source address
+	trusted := valid || request.Protocol == model.RequestProtocolTCP
+
+	if c.allow(request.ClientIP, trusted) {
+		return clientCookie, nil
+	}
+
+	response = new(dns.Msg)
+
+	switch {
+	case trusted:
+		response.SetRcode(request.Req, dns.RcodeRefused)
+	case clientCookie != nil:
+		// the client can retry with the server cookie of this response to get the higher limit
+		response.SetRcode(request.Req, dns.RcodeBadCookie)
+	default:
+		// make the client retry over TCP
+		response.SetReply(request.Req)
+		response.Truncated = true
+	}
+
+	return clientCookie, response
+}
+
+// allow returns true if the client didn't exceed its rate limit
+func (c *dnsCookies) allow(ip net.IP, trusted bool) bool {
+	limit := c.cfg.RateLimit
+	key := ip.String()
+
+	if trusted {
+		limit = c.cfg.ValidCookieRateLimit
+		key = "trusted:" + key
+	}
+
+	if limit == 0 {
+		return true
+	}
+
+	limiter, _ := c.limiters.Get(key)
+	if limiter == nil {
+		limiter = rate.NewLimiter(rate.Limit(limit), int(limit))
+	}
+
+	// refresh the TTL so active clients keep their state
+	c.limiters.Put(key, limiter, rateLimiterTTL)
+
+	return limiter.Allow()
+}
+
+// addToResponse adds the client cookie and a new server cookie to the response
+func (c *dnsCookies) addToResponse(request *model.Request, clientCookie []byte, response *dns.Msg) {
+	if clientCookie == nil {
+		// don't pass on a cookie an upstream sent to us
+		util.StripEdns0Option[*dns.EDNS0_COOKIE](response)
+
+		return
+	}
+
+	cookie := slices.Concat(clientCookie, c.serverCookie(clientCookie, request.ClientIP, time.Now()))
+
+	// also required for BADCOOKIE: extended RCODEs are stored in the OPT record
+	util.SetEdns0Option(response, &dns.EDNS0_COOKIE{
+		Code:   dns.EDNS0COOKIE,
+		Cookie: hex.EncodeToString(cookie),
+	})
+}
+
+// serverCookie creates a server cookie as described in RFC 9018
+func (c *dnsCookies) serverCookie(clientCookie []byte, clientIP net.IP, now time.Time) []byte {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return createServerCookie(c.current, clientCookie, clientIP, uint32(now.Unix()))
+}
+
+func (c *dnsCookies) isValidServerCookie(cookie []byte, clientIP net.IP) bool {
+	if len(cookie) != clientCookieLen+serverCookieLen {
+		// not created by us
+		return false
+	}
+
+	clientCookie := cookie[:clientCookieLen]
+	serverCookie := cookie[clientCookieLen:]
+
+	if serverCookie[0] != serverCookieVersion {
+		return false
+	}
+
+	timestamp := binary.BigEndian.Uint32(serverCookie[4:8])
+	created := time.Unix(int64(timestamp), 0)
+
+	if time.Since(created) > serverCookieMaxAge || time.Until(created) > serverCookieMaxFuture {
+		return false
+	}
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for _, secret := range [][cookieSecretLen]byte{c.current, c.previous} {
+		expected := createServerCookie(secret, clientCookie, clientIP, timestamp)
+
+		if string(expected) == string(serverCookie) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// createServerCookie returns Version | Reserved | Timestamp | Hash,
+// with Hash = SipHash-2-4(Client Cookie | Version | Reserved | Timestamp | Client-IP, Server Secret)
+func createServerCookie(secret [cookieSecretLen]byte, clientCookie []byte, clientIP net.IP, timestamp uint32) []byte {
+	cookie := make([]byte, serverCookieLen)
+	cookie[0] = serverCookieVersion
+	binary.BigEndian.PutUint32(cookie[4:8], timestamp)
+
+	if ip4 := clientIP.To4(); ip4 != nil {
+		clientIP = ip4
+	}
+
+	input := make([]byte, 0, clientCookieLen+8+net.IPv6len) //nolint:mnd // version, reserved and timestamp
+	input = append(input, clientCookie...)
+	input = append(input, cookie[:8]...)
+	input = append(input, clientIP...)
+
+	hash := siphash.New(secret[:])
+	_, _ = hash.Write(input)
+
+	return hash.Sum(cookie[:8])
+}
+
+func isValidCookieLen(l int) bool {
+	return l == clientCookieLen ||
+		(l >= clientCookieLen+serverCookieMinLen && l <= clientCookieLen+serverCookieMaxLen)
+}
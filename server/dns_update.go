@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+
+	"github.com/Abiji-2020/bGuard/log"
+	"github.com/Abiji-2020/bGuard/model"
+	"github.com/Abiji-2020/bGuard/resolver"
+	"github.com/miekg/dns"
+	"github.com/sirupsen/logrus"
+)
+
+// flag of the DNS header bits for responses
+const headerBitQR = 1 << 15
+
+// acceptMsg accepts dynamic updates (RFC 2136) in addition to the messages accepted by default,
+// which rejects them as their sections can contain many records
+func acceptMsg(dh dns.Header) dns.MsgAcceptAction {
+	isResponse := dh.Bits&headerBitQR != 0
+	opcode := int(dh.Bits>>11) & 0xF //nolint:mnd // opcode bits of the header
+
+	if !isResponse && opcode == dns.OpcodeUpdate && dh.Qdcount == 1 {
+		return dns.MsgAccept
+	}
+
+	return dns.DefaultMsgAcceptFunc(dh)
+}
+
+// handleUpdate handles dynamic updates (RFC 2136) of authoritative zones
+func (s *Server) handleUpdate(ctx context.Context, request *model.Request, w dns.ResponseWriter) {
+	logger := log.FromCtx(ctx).WithFields(logrus.Fields{
+		"zone":      request.Req.Question[0].Name,
+		"client_ip": request.ClientIP,
+	})
+
+	authoritative, err := resolver.GetFromChainWithType[*resolver.AuthoritativeResolver](s.queryResolver)
+	if err != nil || !authoritative.IsEnabled() {
+		writeRcode(ctx, w, request.Req, dns.RcodeNotImplemented)
+
+		return
+	}
+
+	rcode := authoritative.Update(ctx, request, verifiedTSIGKey(w, request.Req))
+	if rcode != dns.RcodeSuccess {
+		logger.Warnf("dynamic update failed: %s", dns.RcodeToString[rcode])
+	}
+
+	writeRcode(ctx, w, request.Req, rcode)
+}
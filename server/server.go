@@ -52,6 +52,9 @@ type Server struct {
 	httpMux        *chi.Mux
 	httpsMux       *chi.Mux
 	cert           tls.Certificate
+	cookies        *dnsCookies
+	// nil if the block page is disabled
+	blockPage *resolver.BlockingResolver
 }
 
 func logger() *logrus.Entry {
@@ -161,6 +164,17 @@ func NewServer(ctx context.Context, cfg *config.Config) (server *Server, err err
 		cert:           cert,
 	}
 
+	if cfg.DNSCookies.IsEnabled() {
+		server.cookies = newDNSCookies(ctx, cfg.DNSCookies)
+	}
+
+	if cfg.Blocking.BlockPage.IsEnabled() {
+		server.blockPage, err = resolver.GetFromChainWithType[*resolver.BlockingResolver](queryResolver)
+		if err != nil {
+			return nil, fmt.Errorf("no block page implementation found %w", err)
+		}
+	}
+
 	server.printConfiguration()
 
 	server.registerDNSHandlers(ctx)
@@ -204,6 +218,14 @@ func createServers(cfg *config.Config, cert tls.Certificate) ([]*dns.Server, err
 			return createTLSServer(cfg, address, cert)
 		}, cfg.Ports.TLS))
 
+	// always set, so signed messages with unknown keys fail the verification
+	tsigSecrets := cfg.Authoritative.TSIGSecrets()
+
+	for _, server := range dnsServers {
+		server.TsigSecret = tsigSecrets
+		server.MsgAcceptFunc = acceptMsg
+	}
+
 	return dnsServers, err.ErrorOrNil()
 }
 
@@ -380,11 +402,16 @@ func createQueryResolver(
 	bootstrap *resolver.Bootstrap,
 	redisClient *redis.Client,
 ) (resolver.ChainedResolver, error) {
-	upstreamTree, utErr := resolver.NewUpstreamTreeResolver(ctx, cfg.Upstreams, bootstrap)
-	blocking, blErr := resolver.NewBlockingResolver(ctx, cfg.Blocking, redisClient, bootstrap)
-	clientNames, cnErr := resolver.NewClientNamesResolver(ctx, cfg.ClientLookup, cfg.Upstreams, bootstrap)
+	upstreamTree, utErr := resolver.NewUpstreamTreeResolver(ctx, cfg.Upstreams, cfg.Profiles, bootstrap)
+	blocking, blErr := resolver.NewBlockingResolver(ctx, cfg.Blocking, cfg.Profiles, redisClient, bootstrap)
+	dhcpLeases := resolver.NewDHCPLeasesResolver(ctx, cfg.DHCPLeases)
+	clientNames, cnErr := resolver.NewClientNamesResolver(
+		ctx, cfg.ClientLookup, cfg.Upstreams, bootstrap, dhcpLeases.Leases(),
+	)
 	condUpstream, cuErr := resolver.NewConditionalUpstreamResolver(ctx, cfg.Conditional, cfg.Upstreams, bootstrap)
 	hostsFile, hfErr := resolver.NewHostsFileResolver(ctx, cfg.HostsFile, bootstrap)
+	authoritative, auErr := resolver.NewAuthoritativeResolver(ctx, cfg.Authoritative, redisClient)
+	safeSearch, ssErr := resolver.NewSafeSearchResolver(ctx, cfg.SafeSearch, cfg.Profiles, bootstrap)
 
 	err := multierror.Append(
 		multierror.Prefix(utErr, "upstream tree resolver: "),
@@ -392,23 +419,31 @@ func createQueryResolver(
 		multierror.Prefix(cnErr, "client names resolver: "),
 		multierror.Prefix(cuErr, "conditional upstream resolver: "),
 		multierror.Prefix(hfErr, "hosts file resolver: "),
+		multierror.Prefix(auErr, "authoritative resolver: "),
+		multierror.Prefix(ssErr, "safe search resolver: "),
 	).ErrorOrNil()
 	if err != nil {
 		return nil, err
 	}
 
 	r := resolver.Chain(
-		resolver.NewFilteringResolver(cfg.Filtering),
-		resolver.NewFQDNOnlyResolver(cfg.FQDNOnly),
 		resolver.NewECSResolver(cfg.ECS),
 		clientNames,
+		resolver.NewProfileResolver(cfg.Profiles),
+		resolver.NewFilteringResolver(cfg.Filtering, cfg.Profiles),
+		resolver.NewFQDNOnlyResolver(cfg.FQDNOnly, cfg.Profiles),
 		resolver.NewEDEResolver(cfg.EDE),
 		resolver.NewQueryLoggingResolver(ctx, cfg.QueryLog),
 		resolver.NewMetricsResolver(cfg.Prometheus),
+		authoritative,
 		resolver.NewRewriterResolver(cfg.CustomDNS.RewriterConfig, resolver.NewCustomDNSResolver(cfg.CustomDNS)),
 		hostsFile,
+		dhcpLeases,
+		resolver.NewDNS64Resolver(cfg.DNS64),
 		blocking,
+		safeSearch,
 		resolver.NewCachingResolver(ctx, cfg.Caching, redisClient),
+		resolver.NewRebindingResolver(cfg.Rebinding),
 		resolver.NewRewriterResolver(cfg.Conditional.RewriterConfig, condUpstream),
 		resolver.NewSpecialUseDomainNamesResolver(cfg.SUDN),
 		upstreamTree,
@@ -437,6 +472,11 @@ func (s *Server) printConfiguration() {
 		log.WithIndent(logger(), "  ", s.cfg.Redis.LogConfig)
 	}
 
+	if s.cfg.DNSCookies.IsEnabled() {
+		logger().Info("DNS cookies:")
+		log.WithIndent(logger(), "  ", s.cfg.DNSCookies.LogConfig)
+	}
+
 	resolver.ForEach(s.queryResolver, func(res resolver.Resolver) {
 		resolver.LogResolverConfig(res, logger())
 	})
@@ -501,7 +541,7 @@ func (s *Server) Start(ctx context.Context, errCh chan<- error) {
 				ReadTimeout:       readTimeout,
 				ReadHeaderTimeout: readHeaderTimeout,
 				WriteTimeout:      writeTimeout,
-				Handler:           s.httpsMux,
+				Handler:           s.withBlockPage(s.httpsMux),
 			}
 
 			if err := srv.Serve(listener); err != nil {
@@ -518,7 +558,7 @@ func (s *Server) Start(ctx context.Context, errCh chan<- error) {
 			logger().Infof("https server is up and running on addr/port %s", address)
 
 			server := http.Server{
-				Handler:           s.httpsMux,
+				Handler:           s.withBlockPage(s.httpsMux),
 				ReadTimeout:       readTimeout,
 				ReadHeaderTimeout: readHeaderTimeout,
 				WriteTimeout:      writeTimeout,
@@ -604,7 +644,13 @@ func newRequestFromDNS(ctx context.Context, rw dns.ResponseWriter, msg *dns.Msg)
 		clientID = extractClientIDFromHost(con.ConnectionState().ServerName)
 	}
 
-	return newRequest(ctx, clientIP, clientID, protocol, msg)
+	ctx, request := newRequest(ctx, clientIP, clientID, protocol, msg)
+
+	if rw != nil && rw.LocalAddr() != nil {
+		request.Listener = rw.LocalAddr().String()
+	}
+
+	return ctx, request
 }
 
 func newRequestFromHTTP(ctx context.Context, req *http.Request, msg *dns.Msg) (context.Context, *model.Request) {
@@ -616,13 +662,34 @@ func newRequestFromHTTP(ctx context.Context, req *http.Request, msg *dns.Msg) (c
 		clientID = extractClientIDFromHost(req.Host)
 	}
 
-	return newRequest(ctx, clientIP, clientID, protocol, msg)
+	ctx, request := newRequest(ctx, clientIP, clientID, protocol, msg)
+	request.Listener = req.URL.Path
+
+	return ctx, request
 }
 
 // OnRequest will be executed if a new DNS request is received
 func (s *Server) OnRequest(ctx context.Context, w dns.ResponseWriter, msg *dns.Msg) {
 	ctx, request := newRequestFromDNS(ctx, w, msg)
 
+	if isZoneTransfer(msg) {
+		s.handleZoneTransfer(ctx, request, w)
+
+		return
+	}
+
+	if isNotify(msg) {
+		s.handleNotify(ctx, request, w)
+
+		return
+	}
+
+	if msg.Opcode == dns.OpcodeUpdate {
+		s.handleUpdate(ctx, request, w)
+
+		return
+	}
+
 	s.handleReq(ctx, request, w)
 }
 
@@ -631,18 +698,50 @@ type msgWriter interface {
 }
 
 func (s *Server) handleReq(ctx context.Context, request *model.Request, w msgWriter) {
+	var clientCookie []byte
+
+	if s.cookies != nil {
+		var cookieResponse *dns.Msg
+
+		clientCookie, cookieResponse = s.cookies.check(request)
+		if cookieResponse != nil {
+			s.cookies.addToResponse(request, clientCookie, cookieResponse)
+
+			err := w.WriteMsg(cookieResponse)
+			util.LogOnError(ctx, "can't write message: ", err)
+
+			return
+		}
+	}
+
+	var m *dns.Msg
+
 	response, err := s.resolve(ctx, request)
 	if err != nil {
 		log.FromCtx(ctx).Error("error on processing request:", err)
 
-		m := new(dns.Msg)
+		m = new(dns.Msg)
+		m.SetRcode(request.Req, dns.RcodeServerFailure)
+	} else if response.Drop {
+		// a DoH client always gets an HTTP response, so it can't see a dropped query as a timeout
+		if _, isDoH := w.(httpMsgWriter); !isDoH {
+			return
+		}
+
+		m = new(dns.Msg)
 		m.SetRcode(request.Req, dns.RcodeServerFailure)
-		err := w.WriteMsg(m)
-		util.LogOnError(ctx, "can't write message: ", err)
 	} else {
-		err := w.WriteMsg(response.Res)
-		util.LogOnError(ctx, "can't write message: ", err)
+		m = response.Res
+	}
+
+	if s.cookies != nil {
+		s.cookies.addToResponse(request, clientCookie, m)
+		// the cookie might not fit anymore
+		m.Truncate(getMaxResponseSize(request))
 	}
+
+	err = w.WriteMsg(m)
+	util.LogOnError(ctx, "can't write message: ", err)
 }
 
 func (s *Server) resolve(ctx context.Context, request *model.Request) (response *model.Response, rerr error) {
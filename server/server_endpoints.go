@@ -8,6 +8,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/Abiji-2020/bGuard/resolver"
@@ -81,6 +82,18 @@ func (s *Server) registerAPIEndpoints(router *chi.Mux) error {
 	router.Post(pathDohQuery+"/", s.dohPostRequestHandler)
 	router.Post(pathDohQuery+"/{clientID}", s.dohPostRequestHandler)
 
+	// profile paths have their own routes, so their last segment isn't used as client ID
+	for _, profile := range s.cfg.Profiles {
+		for _, listener := range profile.Listeners {
+			if path := strings.TrimSuffix(listener.Path, "/"); strings.HasPrefix(path, pathDohQuery+"/") {
+				router.Get(path, s.dohGetRequestHandler)
+				router.Get(path+"/", s.dohGetRequestHandler)
+				router.Post(path, s.dohPostRequestHandler)
+				router.Post(path+"/", s.dohPostRequestHandler)
+			}
+		}
+	}
+
 	return nil
 }
 
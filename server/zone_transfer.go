@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/Abiji-2020/bGuard/log"
+	"github.com/Abiji-2020/bGuard/model"
+	"github.com/Abiji-2020/bGuard/resolver"
+	"github.com/Abiji-2020/bGuard/util"
+	"github.com/miekg/dns"
+	"github.com/sirupsen/logrus"
+)
+
+// number of records sent per message of a zone transfer
+const transferChunkSize = 100
+
+func isZoneTransfer(msg *dns.Msg) bool {
+	if len(msg.Question) != 1 {
+		return false
+	}
+
+	qType := msg.Question[0].Qtype
+
+	return qType == dns.TypeAXFR || qType == dns.TypeIXFR
+}
+
+// handleZoneTransfer sends an authoritative zone to a secondary (RFC 5936, RFC 1995)
+func (s *Server) handleZoneTransfer(ctx context.Context, request *model.Request, w dns.ResponseWriter) {
+	logger := log.FromCtx(ctx).WithFields(logrus.Fields{
+		"zone":      request.Req.Question[0].Name,
+		"type":      dns.TypeToString[request.Req.Question[0].Qtype],
+		"client_ip": request.ClientIP,
+	})
+
+	authoritative, err := resolver.GetFromChainWithType[*resolver.AuthoritativeResolver](s.queryResolver)
+	if err != nil || !authoritative.IsEnabled() {
+		writeRcode(ctx, w, request.Req, dns.RcodeRefused)
+
+		return
+	}
+
+	rrs, rcode := authoritative.Transfer(request, verifiedTSIGKey(w, request.Req))
+	if rcode != dns.RcodeSuccess {
+		logger.Warnf("zone transfer denied: %s", dns.RcodeToString[rcode])
+		writeRcode(ctx, w, request.Req, rcode)
+
+		return
+	}
+
+	if request.Protocol == model.RequestProtocolUDP {
+		s.handleUDPZoneTransfer(ctx, request, w, rrs)
+
+		return
+	}
+
+	logger.Infof("sending zone transfer with %d records", len(rrs))
+
+	ch := make(chan *dns.Envelope)
+
+	go func() {
+		defer close(ch)
+
+		for start := 0; start < len(rrs); start += transferChunkSize {
+			ch <- &dns.Envelope{RR: rrs[start:min(start+transferChunkSize, len(rrs))]}
+		}
+	}()
+
+	tr := new(dns.Transfer)
+
+	err = tr.Out(w, request.Req, ch)
+	util.LogOnError(ctx, "can't send zone transfer: ", err)
+}
+
+// handleUDPZoneTransfer answers IXFR queries over UDP: only the "up to date" response fits a single message,
+// for everything else the secondary must retry over TCP (RFC 1995 section 2)
+func (s *Server) handleUDPZoneTransfer(ctx context.Context, request *model.Request, w dns.ResponseWriter, rrs []dns.RR) {
+	m := new(dns.Msg)
+	m.SetReply(request.Req)
+	m.Authoritative = true
+	signLike(m, request.Req)
+
+	switch {
+	case request.Req.Question[0].Qtype == dns.TypeAXFR:
+		m.Rcode = dns.RcodeFormatError
+	case len(rrs) == 1:
+		m.Answer = rrs
+	default:
+		m.Truncated = true
+	}
+
+	err := w.WriteMsg(m)
+	util.LogOnError(ctx, "can't write message: ", err)
+}
+
+// handleNotify handles NOTIFY messages (RFC 1996) of the primaries of secondary zones
+func (s *Server) handleNotify(ctx context.Context, request *model.Request, w dns.ResponseWriter) {
+	logger := log.FromCtx(ctx).WithFields(logrus.Fields{
+		"zone":      request.Req.Question[0].Name,
+		"client_ip": request.ClientIP,
+	})
+
+	authoritative, err := resolver.GetFromChainWithType[*resolver.AuthoritativeResolver](s.queryResolver)
+	if err != nil || !authoritative.IsEnabled() {
+		writeRcode(ctx, w, request.Req, dns.RcodeNotImplemented)
+
+		return
+	}
+
+	rcode := authoritative.Notify(request, verifiedTSIGKey(w, request.Req))
+	if rcode != dns.RcodeSuccess {
+		logger.Warnf("NOTIFY denied: %s", dns.RcodeToString[rcode])
+	} else {
+		logger.Info("received NOTIFY")
+	}
+
+	writeRcode(ctx, w, request.Req, rcode)
+}
+
+// isNotify checks for a NOTIFY message announcing a changed SOA
+func isNotify(msg *dns.Msg) bool {
+	return msg.Opcode == dns.OpcodeNotify && len(msg.Question) == 1 && msg.Question[0].Qtype == dns.TypeSOA
+}
+
+// verifiedTSIGKey returns the name of the TSIG key the message is signed with,
+// or an empty string if it isn't signed or the signature is invalid
+func verifiedTSIGKey(w dns.ResponseWriter, msg *dns.Msg) string {
+	tsig := msg.IsTsig()
+	if tsig == nil || w.TsigStatus() != nil {
+		return ""
+	}
+
+	return dns.CanonicalName(tsig.Hdr.Name)
+}
+
+// signLike signs the response with the key of the request, the signature is created when it is written
+func signLike(response, request *dns.Msg) {
+	if tsig := request.IsTsig(); tsig != nil {
+		response.SetTsig(tsig.Hdr.Name, tsig.Algorithm, tsig.Fudge, time.Now().Unix())
+	}
+}
+
+func writeRcode(ctx context.Context, w dns.ResponseWriter, request *dns.Msg, rcode int) {
+	m := new(dns.Msg)
+	m.SetRcode(request, rcode)
+	m.Authoritative = rcode == dns.RcodeSuccess
+
+	if w.TsigStatus() == nil {
+		signLike(m, request)
+	}
+
+	err := w.WriteMsg(m)
+	util.LogOnError(ctx, "can't write message: ", err)
+}
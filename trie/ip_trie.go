@@ -0,0 +1,115 @@
+package trie
+
+import (
+	"net"
+	"net/netip"
+)
+
+// ipv4MappedBits is the length of the prefix of IPv4-mapped IPv6 addresses (::ffff:0:0/96)
+const ipv4MappedBits = 96
+
+// IPTrie maps IP prefixes to values and finds the value of the longest prefix containing an IP.
+//
+// It is a binary trie over the bits of the addresses, IPv4 addresses are stored as IPv4-mapped
+// IPv6 addresses, so both share one trie. A lookup takes at most 128 steps, regardless of the
+// number of prefixes.
+type IPTrie[T any] struct {
+	root ipNode[T]
+	size int
+}
+
+type ipNode[T any] struct {
+	children [2]*ipNode[T]
+	value    T
+	hasValue bool
+}
+
+func NewIPTrie[T any]() *IPTrie[T] {
+	return &IPTrie[T]{}
+}
+
+// Len returns the number of prefixes
+func (t *IPTrie[T]) Len() int {
+	return t.size
+}
+
+// Insert adds the prefix with its value, the value of an existing prefix is replaced
+func (t *IPTrie[T]) Insert(prefix netip.Prefix, value T) {
+	addr, bits := normalize(prefix)
+	n := &t.root
+
+	for i := 0; i < bits; i++ {
+		bit := bitAt(addr, i)
+
+		if n.children[bit] == nil {
+			n.children[bit] = &ipNode[T]{}
+		}
+
+		n = n.children[bit]
+	}
+
+	if !n.hasValue {
+		t.size++
+	}
+
+	n.value = value
+	n.hasValue = true
+}
+
+// Get returns the value of the exact prefix
+func (t *IPTrie[T]) Get(prefix netip.Prefix) (value T, ok bool) {
+	addr, bits := normalize(prefix)
+	n := &t.root
+
+	for i := 0; i < bits && n != nil; i++ {
+		n = n.children[bitAt(addr, i)]
+	}
+
+	if n == nil || !n.hasValue {
+		return value, false
+	}
+
+	return n.value, true
+}
+
+// LongestMatch returns the value of the longest prefix containing the IP
+func (t *IPTrie[T]) LongestMatch(ip net.IP) (value T, ok bool) {
+	addr, isValid := netip.AddrFromSlice(ip)
+	if !isValid {
+		return value, false
+	}
+
+	// IPv4 addresses are returned as IPv4-mapped IPv6 addresses
+	addr16 := addr.As16()
+	n := &t.root
+
+	for i := 0; n != nil; i++ {
+		if n.hasValue {
+			value, ok = n.value, true
+		}
+
+		if i == net.IPv6len*8 {
+			break
+		}
+
+		n = n.children[bitAt(addr16, i)]
+	}
+
+	return value, ok
+}
+
+// normalize returns the address as 16 bytes and the prefix length in IPv6 bits
+func normalize(prefix netip.Prefix) ([16]byte, int) {
+	prefix = prefix.Masked()
+	bits := prefix.Bits()
+
+	if prefix.Addr().Is4() {
+		bits += ipv4MappedBits
+	}
+
+	return prefix.Addr().As16(), bits
+}
+
+func bitAt(addr [16]byte, i int) byte {
+	return (addr[i/8] >> (7 - i%8)) & 1 //nolint:mnd
+}
@@ -95,6 +95,34 @@ func RemoveEdns0Option[T EDNS0Option](msg *dns.Msg) bool {
 	return res
 }
 
+// StripEdns0Option removes the option according to the given type from the OPT record
+// in the Extra section of the given message.
+// Unlike RemoveEdns0Option, the OPT record is kept, even without options, so the
+// EDNS0 parameters like the UDP buffer size are preserved.
+// If the option is successfully removed, true will be returned.
+func StripEdns0Option[T EDNS0Option](msg *dns.Msg) bool {
+	if msg == nil {
+		return false
+	}
+
+	opt := msg.IsEdns0()
+	if opt == nil {
+		return false
+	}
+
+	var t T
+
+	for i, o := range opt.Option {
+		if o.Option() == t.Option() {
+			opt.Option = slices.Delete(opt.Option, i, i+1)
+
+			return true
+		}
+	}
+
+	return false
+}
+
 // SetEdns0Option adds the given option to the OPT record in the Extra section of the
 // given message.
 // If the option already exists, it will be replaced.
@@ -126,3 +154,49 @@ func SetEdns0Option(msg *dns.Msg, opt dns.EDNS0) bool {
 
 	return true
 }
+
+// GetEdns0LocalOption returns the local option (RFC 6891 section 9) with the given code
+// from the OPT record in the Extra section of the given message.
+// If the option is not found, nil will be returned.
+func GetEdns0LocalOption(msg *dns.Msg, code uint16) *dns.EDNS0_LOCAL {
+	if msg == nil {
+		return nil
+	}
+
+	opt := msg.IsEdns0()
+	if opt == nil {
+		return nil
+	}
+
+	for _, o := range opt.Option {
+		if local, ok := o.(*dns.EDNS0_LOCAL); ok && local.Code == code {
+			return local
+		}
+	}
+
+	return nil
+}
+
+// StripEdns0LocalOption removes the local option with the given code from the OPT record
+// in the Extra section of the given message, keeping the OPT record.
+// If the option is successfully removed, true will be returned.
+func StripEdns0LocalOption(msg *dns.Msg, code uint16) bool {
+	if msg == nil {
+		return false
+	}
+
+	opt := msg.IsEdns0()
+	if opt == nil {
+		return false
+	}
+
+	for i, o := range opt.Option {
+		if local, ok := o.(*dns.EDNS0_LOCAL); ok && local.Code == code {
+			opt.Option = slices.Delete(opt.Option, i, i+1)
+
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,95 @@
+package util
+
+import (
+	"net/netip"
+	"strings"
+)
+
+// ParseIPPrefixes parses an IP address, a CIDR subnet or an IP range ("<first>-<last>") into prefixes.
+// ok is false if s is none of them.
+func ParseIPPrefixes(s string) (prefixes []netip.Prefix, ok bool) {
+	s = strings.TrimSpace(s)
+
+	if first, last, isRange := strings.Cut(s, "-"); isRange {
+		from, err := netip.ParseAddr(strings.TrimSpace(first))
+		if err != nil {
+			return nil, false
+		}
+
+		to, err := netip.ParseAddr(strings.TrimSpace(last))
+		if err != nil || from.Is4() != to.Is4() || to.Less(from) {
+			return nil, false
+		}
+
+		return RangeToPrefixes(from, to), true
+	}
+
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, false
+		}
+
+		return []netip.Prefix{prefix.Masked()}, true
+	}
+
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return nil, false
+	}
+
+	return []netip.Prefix{netip.PrefixFrom(addr, addr.BitLen())}, true
+}
+
+// RangeToPrefixes returns the smallest set of prefixes covering the addresses from first to last
+func RangeToPrefixes(first, last netip.Addr) []netip.Prefix {
+	var prefixes []netip.Prefix
+
+	for first.IsValid() && !last.Less(first) {
+		// the largest prefix starting at first, which ends before last
+		prefix := netip.PrefixFrom(first, first.BitLen())
+
+		for bits := first.BitLen() - 1; bits >= 0; bits-- {
+			candidate := netip.PrefixFrom(first, bits)
+			if candidate.Masked().Addr() != first || last.Less(lastAddr(candidate)) {
+				break
+			}
+
+			prefix = candidate
+		}
+
+		prefixes = append(prefixes, prefix)
+
+		end := lastAddr(prefix)
+		if end == last {
+			break
+		}
+
+		first = end.Next()
+	}
+
+	return prefixes
+}
+
+// lastAddr returns the last address of the prefix
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	addr := prefix.Masked().Addr()
+
+	if addr.Is4() {
+		b := addr.As4()
+		setHostBits(b[:], prefix.Bits())
+
+		return netip.AddrFrom4(b)
+	}
+
+	b := addr.As16()
+	setHostBits(b[:], prefix.Bits())
+
+	return netip.AddrFrom16(b)
+}
+
+func setHostBits(b []byte, bits int) {
+	for i := bits; i < len(b)*8; i++ {
+		b[i/8] |= 1 << (7 - i%8) //nolint:mnd
+	}
+}
@@ -10,6 +10,11 @@ import (
 //go:embed index.html
 var IndexTmpl string
 
+// BlockPageTmpl html template for the page served for blocked domains
+//
+//go:embed blockpage.html
+var BlockPageTmpl string
+
 //go:embed all:static
 var static embed.FS
 
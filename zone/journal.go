@@ -0,0 +1,105 @@
+package zone
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+
+	"github.com/miekg/dns"
+)
+
+const journalFileMode = 0o600
+
+// Journal persists the changes of a zone, so dynamic updates survive restarts.
+// The changes are stored as IXFR difference sequences in zone file format.
+type Journal struct {
+	path string
+	lock sync.Mutex
+}
+
+// NewJournal creates a journal stored in the file at path
+func NewJournal(path string) *Journal {
+	return &Journal{path: path}
+}
+
+// Path returns the path of the journal file
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Append adds differences to the journal and syncs it to disk
+func (j *Journal) Append(diffs []dns.RR) (err error) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, journalFileMode)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = errors.Join(err, f.Close())
+	}()
+
+	w := bufio.NewWriter(f)
+
+	for _, rr := range diffs {
+		if _, err := fmt.Fprintln(w, rr.String()); err != nil {
+			return err
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	return f.Sync()
+}
+
+// Replay applies all differences of the journal to the zone.
+// A missing journal file is not an error.
+func (j *Journal) Replay(z *Zone) error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	f, err := os.Open(j.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	zoneParser := dns.NewZoneParser(f, z.Origin(), j.path)
+
+	var diffs []dns.RR
+
+	for rr, ok := zoneParser.Next(); ok; rr, ok = zoneParser.Next() {
+		diffs = append(diffs, rr)
+	}
+
+	if err := zoneParser.Err(); err != nil {
+		return err
+	}
+
+	return z.ApplyDifferences(diffs)
+}
+
+// Reset removes all differences from the journal
+func (j *Journal) Reset() error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	err := os.Remove(j.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
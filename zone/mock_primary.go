@@ -0,0 +1,172 @@
+package zone
+
+import (
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/miekg/dns"
+)
+
+// MockPrimary is an in-process primary server for tests.
+// It answers SOA queries and zone transfers for its zone and can send NOTIFY messages.
+type MockPrimary struct {
+	zone *Zone
+	tsig *TSIG
+
+	udp *dns.Server
+	tcp *dns.Server
+
+	lock      sync.Mutex
+	transfers map[uint16]int
+}
+
+// NewMockPrimary starts a primary for z on a random local port.
+// If tsig is not nil, all requests must be signed with it.
+func NewMockPrimary(z *Zone, tsig *TSIG) (*MockPrimary, error) {
+	p := &MockPrimary{
+		zone:      z,
+		tsig:      tsig,
+		transfers: make(map[uint16]int),
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	packetConn, err := net.ListenPacket("udp", listener.Addr().String())
+	if err != nil {
+		listener.Close()
+
+		return nil, err
+	}
+
+	p.tcp = &dns.Server{Listener: listener, Handler: p, TsigSecret: tsig.secrets()}
+	p.udp = &dns.Server{PacketConn: packetConn, Handler: p, TsigSecret: tsig.secrets()}
+
+	for _, srv := range []*dns.Server{p.tcp, p.udp} {
+		started := make(chan struct{})
+		srv.NotifyStartedFunc = func() { close(started) }
+
+		go func() {
+			_ = srv.ActivateAndServe()
+		}()
+
+		<-started
+	}
+
+	return p, nil
+}
+
+// Addr returns the address the primary listens on, for UDP and TCP
+func (p *MockPrimary) Addr() string {
+	return p.tcp.Listener.Addr().String()
+}
+
+// Zone returns the served zone
+func (p *MockPrimary) Zone() *Zone {
+	return p.zone
+}
+
+// Transfers returns the number of zone transfers of the given type (AXFR or IXFR)
+func (p *MockPrimary) Transfers(qType uint16) int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.transfers[qType]
+}
+
+// Close stops the primary
+func (p *MockPrimary) Close() {
+	_ = p.tcp.Shutdown()
+	_ = p.udp.Shutdown()
+}
+
+// Notify sends a NOTIFY message for the zone to a secondary and waits for the answer
+func (p *MockPrimary) Notify(secondary string) error {
+	msg := new(dns.Msg)
+	msg.SetNotify(p.zone.Origin())
+	msg.Answer = []dns.RR{p.zone.SOA()}
+	p.tsig.sign(msg)
+
+	client := &dns.Client{
+		Net:        "udp",
+		Timeout:    exchangeTimeout,
+		TsigSecret: p.tsig.secrets(),
+	}
+
+	response, _, err := client.Exchange(msg, secondary)
+	if err != nil {
+		return err
+	}
+
+	if response.Rcode != dns.RcodeSuccess {
+		return errors.New(dns.RcodeToString[response.Rcode])
+	}
+
+	return p.tsig.verify(response)
+}
+
+// ServeDNS implements `dns.Handler`.
+func (p *MockPrimary) ServeDNS(w dns.ResponseWriter, request *dns.Msg) {
+	response := new(dns.Msg)
+	response.SetReply(request)
+	response.Authoritative = true
+
+	if p.tsig != nil {
+		if request.IsTsig() == nil || w.TsigStatus() != nil {
+			response.Rcode = dns.RcodeNotAuth
+
+			_ = w.WriteMsg(response)
+
+			return
+		}
+
+		p.tsig.sign(response)
+	}
+
+	question := request.Question[0]
+
+	switch {
+	case dns.CanonicalName(question.Name) != p.zone.Origin():
+		response.Rcode = dns.RcodeNotAuth
+
+	case question.Qtype == dns.TypeSOA:
+		response.Answer = []dns.RR{p.zone.SOA()}
+
+	case question.Qtype == dns.TypeAXFR || question.Qtype == dns.TypeIXFR:
+		p.transfer(w, request)
+
+		return
+
+	default:
+		response.Rcode = dns.RcodeRefused
+	}
+
+	_ = w.WriteMsg(response)
+}
+
+func (p *MockPrimary) transfer(w dns.ResponseWriter, request *dns.Msg) {
+	qType := request.Question[0].Qtype
+
+	p.lock.Lock()
+	p.transfers[qType]++
+	p.lock.Unlock()
+
+	rrs := p.zone.AXFR()
+
+	if qType == dns.TypeIXFR {
+		for _, rr := range request.Ns {
+			if soa, ok := rr.(*dns.SOA); ok {
+				rrs = p.zone.IXFR(soa.Serial)
+			}
+		}
+	}
+
+	ch := make(chan *dns.Envelope, 1)
+	ch <- &dns.Envelope{RR: rrs}
+	close(ch)
+
+	_ = new(dns.Transfer).Out(w, request, ch)
+}
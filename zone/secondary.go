@@ -0,0 +1,445 @@
+package zone
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Abiji-2020/bGuard/log"
+	"github.com/miekg/dns"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// retry interval until the zone was transferred the first time
+	initialRetry = 10 * time.Second
+	// lower bound of the SOA timers, protects the primaries from misconfigured zones
+	minRefresh = time.Second
+
+	exchangeTimeout = 5 * time.Second
+	transferTimeout = time.Minute
+
+	defaultDNSPort = "53"
+	tsigFudge      = 300
+)
+
+var ErrUnsigned = errors.New("response is not signed with TSIG")
+
+// TSIG is a key to sign the messages exchanged with primaries (RFC 8945)
+type TSIG struct {
+	// canonical key name
+	Name string
+	// canonical algorithm name
+	Algorithm string
+	// base64 encoded secret
+	Secret string
+}
+
+func (t *TSIG) sign(msg *dns.Msg) {
+	if t != nil {
+		msg.SetTsig(t.Name, t.Algorithm, tsigFudge, time.Now().Unix())
+	}
+}
+
+func (t *TSIG) secrets() map[string]string {
+	if t == nil {
+		return nil
+	}
+
+	return map[string]string{t.Name: t.Secret}
+}
+
+// verify checks that a response to a signed request is signed, too.
+// The signature itself is verified when the response is read.
+func (t *TSIG) verify(msg *dns.Msg) error {
+	if t != nil && msg.IsTsig() == nil {
+		return ErrUnsigned
+	}
+
+	return nil
+}
+
+// Secondary keeps a copy of a zone transferred from primary servers (RFC 1034 section 4.3.5).
+//
+// The zone is added to the zones once it is transferred and removed again when it expires.
+type Secondary struct {
+	origin    string
+	primaries []string
+	tsig      *TSIG
+	zones     *Zones
+	logger    *logrus.Entry
+
+	notify chan struct{}
+
+	lock        sync.RWMutex
+	zone        *Zone
+	lastRefresh time.Time
+}
+
+// NewSecondary creates a secondary zone.
+// primaries are IPs or host names, with an optional port.
+func NewSecondary(origin string, primaries []string, tsig *TSIG, zones *Zones) *Secondary {
+	origin = dns.CanonicalName(origin)
+
+	addresses := make([]string, len(primaries))
+
+	for i, primary := range primaries {
+		if _, _, err := net.SplitHostPort(primary); err != nil {
+			primary = net.JoinHostPort(primary, defaultDNSPort)
+		}
+
+		addresses[i] = primary
+	}
+
+	return &Secondary{
+		origin:    origin,
+		primaries: addresses,
+		tsig:      tsig,
+		zones:     zones,
+		logger:    log.PrefixedLog("secondary").WithField("zone", origin),
+
+		notify: make(chan struct{}, 1),
+	}
+}
+
+// Origin returns the canonical name of the zone apex
+func (s *Secondary) Origin() string {
+	return s.origin
+}
+
+// Zone returns the transferred zone or nil if it was not transferred yet or is expired
+func (s *Secondary) Zone() *Zone {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.zone
+}
+
+// IsPrimary returns true if ip is the address of one of the primaries
+func (s *Secondary) IsPrimary(ip net.IP) bool {
+	return slices.ContainsFunc(s.primaries, func(primary string) bool {
+		host, _, _ := net.SplitHostPort(primary)
+
+		return ip.Equal(net.ParseIP(host))
+	})
+}
+
+// Notify triggers a refresh, as requested by a NOTIFY message of a primary (RFC 1996)
+func (s *Secondary) Notify() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+		// a refresh is already pending
+	}
+}
+
+// Run refreshes the zone according to the SOA timers and NOTIFY messages until ctx is done
+func (s *Secondary) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-timer.C:
+
+		case <-s.notify:
+			if !timer.Stop() {
+				<-timer.C
+			}
+		}
+
+		timer.Reset(s.refresh(ctx))
+	}
+}
+
+// refresh refreshes the zone and returns the time until the next refresh
+func (s *Secondary) refresh(ctx context.Context) time.Duration {
+	err := s.Refresh(ctx)
+
+	s.lock.RLock()
+	z, lastRefresh := s.zone, s.lastRefresh
+	s.lock.RUnlock()
+
+	if z == nil {
+		if err != nil {
+			s.logger.Warn("zone transfer failed: ", err)
+		}
+
+		return initialRetry
+	}
+
+	soa := z.SOA()
+
+	if err == nil {
+		return soaTimer(soa.Refresh)
+	}
+
+	s.logger.Warn("zone refresh failed: ", err)
+
+	if time.Since(lastRefresh) > soaTimer(soa.Expire) {
+		s.logger.Errorf("zone expired, last refresh at %s", lastRefresh.Format(time.RFC3339))
+
+		s.expire()
+
+		return initialRetry
+	}
+
+	return soaTimer(soa.Retry)
+}
+
+func soaTimer(seconds uint32) time.Duration {
+	return max(time.Duration(seconds)*time.Second, minRefresh)
+}
+
+func (s *Secondary) expire() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.zone = nil
+	s.zones.Remove(s.origin)
+}
+
+// Refresh checks the primaries for a newer serial and transfers the zone if needed.
+// The primaries are tried in order until one succeeds.
+func (s *Secondary) Refresh(ctx context.Context) error {
+	errs := make([]error, 0, len(s.primaries))
+
+	for _, primary := range s.primaries {
+		err := s.refreshFrom(ctx, primary)
+		if err == nil {
+			s.lock.Lock()
+			s.lastRefresh = time.Now()
+			s.lock.Unlock()
+
+			return nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", primary, err))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *Secondary) refreshFrom(ctx context.Context, primary string) error {
+	current := s.Zone()
+	if current == nil {
+		return s.load(ctx, primary)
+	}
+
+	serial, err := s.querySerial(ctx, primary)
+	if err != nil {
+		return err
+	}
+
+	if !SerialLess(current.Serial(), serial) {
+		s.logger.Debugf("zone is up to date with serial %d", current.Serial())
+
+		return nil
+	}
+
+	rrs, err := s.transfer(ctx, primary, dns.TypeIXFR, current.Serial())
+	if err == nil && isUnchanged(rrs, current.Serial()) {
+		s.logger.Debugf("primary %s has no changes after serial %d", primary, current.Serial())
+
+		return nil
+	}
+
+	if err == nil {
+		err = current.ApplyTransfer(rrs)
+	}
+
+	if err != nil {
+		s.logger.Debug("incremental zone transfer failed, falling back to full transfer: ", err)
+
+		rrs, err = s.transfer(ctx, primary, dns.TypeAXFR, 0)
+		if err != nil {
+			return err
+		}
+
+		if err := current.ApplyTransfer(rrs); err != nil {
+			return err
+		}
+	}
+
+	s.logger.Infof("zone updated from %s to serial %d", primary, current.Serial())
+
+	return nil
+}
+
+// load creates the zone with a full transfer
+func (s *Secondary) load(ctx context.Context, primary string) error {
+	rrs, err := s.transfer(ctx, primary, dns.TypeAXFR, 0)
+	if err != nil {
+		return err
+	}
+
+	// the transfer ends with the SOA again
+	z, err := New(s.origin, rrs[:len(rrs)-1])
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	s.zone = z
+	s.zones.Add(z)
+	s.lock.Unlock()
+
+	s.logger.Infof("zone transferred from %s with serial %d", primary, z.Serial())
+
+	return nil
+}
+
+// querySerial returns the serial of the primary's SOA
+func (s *Secondary) querySerial(ctx context.Context, primary string) (uint32, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(s.origin, dns.TypeSOA)
+	s.tsig.sign(msg)
+
+	client := &dns.Client{
+		Timeout:    exchangeTimeout,
+		TsigSecret: s.tsig.secrets(),
+	}
+
+	response, _, err := client.ExchangeContext(ctx, msg, primary)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.tsig.verify(response); err != nil {
+		return 0, err
+	}
+
+	if response.Rcode != dns.RcodeSuccess {
+		return 0, fmt.Errorf("SOA query failed: %s", dns.RcodeToString[response.Rcode])
+	}
+
+	for _, rr := range response.Answer {
+		if soa, ok := rr.(*dns.SOA); ok && dns.CanonicalName(soa.Hdr.Name) == s.origin {
+			return soa.Serial, nil
+		}
+	}
+
+	return 0, ErrNoSOA
+}
+
+// transfer requests an AXFR or an IXFR starting at serial over TCP and returns all received records
+func (s *Secondary) transfer(ctx context.Context, primary string, qType uint16, serial uint32) ([]dns.RR, error) {
+	msg := new(dns.Msg)
+
+	if qType == dns.TypeIXFR {
+		// only the serial of the SOA in the authority section is relevant
+		msg.SetIxfr(s.origin, serial, ".", ".")
+	} else {
+		msg.SetAxfr(s.origin)
+	}
+
+	s.tsig.sign(msg)
+
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, "tcp", primary)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &dns.Transfer{
+		Conn:       &dns.Conn{Conn: conn},
+		TsigSecret: s.tsig.secrets(),
+	}
+
+	defer t.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(transferTimeout)); err != nil {
+		return nil, err
+	}
+
+	if err := t.WriteMsg(msg); err != nil {
+		return nil, err
+	}
+
+	var rrs []dns.RR
+
+	for first := true; ; first = false {
+		response, err := t.ReadMsg()
+		if err != nil {
+			return nil, err
+		}
+
+		if first {
+			// further messages may be unsigned (RFC 8945 section 5.3.1)
+			if err := s.tsig.verify(response); err != nil {
+				return nil, err
+			}
+		}
+
+		if response.Rcode != dns.RcodeSuccess {
+			return nil, fmt.Errorf("%s failed: %s", dns.TypeToString[qType], dns.RcodeToString[response.Rcode])
+		}
+
+		rrs = append(rrs, response.Answer...)
+
+		complete, err := isTransferComplete(qType, rrs, serial)
+		if err != nil || complete {
+			return rrs, err
+		}
+	}
+}
+
+// isUnchanged checks if the IXFR response is a single SOA, whose serial isn't newer than serial:
+// the primary doesn't have a newer version of the zone (RFC 1995 section 4)
+func isUnchanged(rrs []dns.RR, serial uint32) bool {
+	if len(rrs) != 1 {
+		return false
+	}
+
+	soa, ok := rrs[0].(*dns.SOA)
+
+	return ok && !SerialLess(serial, soa.Serial)
+}
+
+// isTransferComplete checks if the records form a complete AXFR or IXFR response
+func isTransferComplete(qType uint16, rrs []dns.RR, serial uint32) (bool, error) {
+	if len(rrs) == 0 {
+		return false, nil
+	}
+
+	first, ok := rrs[0].(*dns.SOA)
+	if !ok {
+		return false, ErrNoSOA
+	}
+
+	if len(rrs) == 1 {
+		if qType == dns.TypeIXFR {
+			if isUnchanged(rrs, serial) {
+				return true, nil
+			}
+
+			// the primary can't transfer differences nor the full zone
+			return false, ErrIncompleteTransfer
+		}
+
+		return false, nil
+	}
+
+	count := 0
+
+	for _, rr := range rrs {
+		if soa, ok := rr.(*dns.SOA); ok && soa.Serial == first.Serial {
+			count++
+		}
+	}
+
+	// differences contain the new SOA at the start, before the last additions and at the end
+	if _, incremental := rrs[1].(*dns.SOA); incremental && qType == dns.TypeIXFR {
+		return count == 3, nil //nolint:mnd
+	}
+
+	return count == 2, nil //nolint:mnd
+}
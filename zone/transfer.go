@@ -0,0 +1,155 @@
+package zone
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/miekg/dns"
+)
+
+var ErrIncompleteTransfer = errors.New("zone transfer is incomplete")
+
+// AXFR returns the records of a full zone transfer: all records enclosed by the SOA (RFC 5936)
+func (z *Zone) AXFR() []dns.RR {
+	z.lock.RLock()
+	defer z.lock.RUnlock()
+
+	return z.axfr()
+}
+
+func (z *Zone) axfr() []dns.RR {
+	rrs := z.records()
+
+	return append(rrs, dns.Copy(z.soa))
+}
+
+// IXFR returns the records of an incremental zone transfer to a secondary with the given serial (RFC 1995).
+//
+// If the secondary is up to date, only the SOA is returned.
+// Without a history of the changes the full zone is returned, which RFC 1995 section 4 allows.
+func (z *Zone) IXFR(serial uint32) []dns.RR {
+	z.lock.RLock()
+	defer z.lock.RUnlock()
+
+	if !SerialLess(serial, z.soa.Serial) {
+		return []dns.RR{dns.Copy(z.soa)}
+	}
+
+	return z.axfr()
+}
+
+// SerialLess compares two SOA serials using serial number arithmetic (RFC 1982)
+func SerialLess(a, b uint32) bool {
+	return a != b && int32(b-a) > 0
+}
+
+// ApplyTransfer updates the zone with the records of an AXFR or IXFR response.
+//
+// IXFR responses can be a single SOA if the zone is up to date, a full zone like an AXFR,
+// or a sequence of differences (RFC 1995 section 4).
+func (z *Zone) ApplyTransfer(rrs []dns.RR) error {
+	if len(rrs) == 0 {
+		return ErrIncompleteTransfer
+	}
+
+	first, ok := rrs[0].(*dns.SOA)
+	if !ok {
+		return ErrNoSOA
+	}
+
+	if len(rrs) == 1 {
+		if SerialLess(z.Serial(), first.Serial) {
+			return ErrIncompleteTransfer
+		}
+
+		// up to date
+		return nil
+	}
+
+	if last, ok := rrs[len(rrs)-1].(*dns.SOA); !ok || last.Serial != first.Serial {
+		return ErrIncompleteTransfer
+	}
+
+	if _, ok := rrs[1].(*dns.SOA); !ok {
+		return z.Replace(rrs[:len(rrs)-1])
+	}
+
+	diffs := rrs[1 : len(rrs)-1]
+
+	// the differences must end at the serial of the response
+	for i := len(diffs) - 1; i >= 0; i-- {
+		if soa, ok := diffs[i].(*dns.SOA); ok {
+			if soa.Serial != first.Serial {
+				return ErrIncompleteTransfer
+			}
+
+			break
+		}
+	}
+
+	return z.ApplyDifferences(diffs)
+}
+
+// ApplyDifferences applies sequences of deleted records, starting with the old SOA,
+// and added records, starting with the new SOA (RFC 1995 section 4).
+// The first sequence must start at the zone's serial.
+func (z *Zone) ApplyDifferences(diffs []dns.RR) error {
+	z.update.Lock()
+	defer z.update.Unlock()
+
+	z.lock.RLock()
+	soa := z.soa
+	current := z.records()
+	z.lock.RUnlock()
+
+	records := make(map[string]dns.RR, len(current))
+
+	for _, rr := range current[1:] {
+		records[recordKey(rr)] = rr
+	}
+
+	adding := true
+
+	for _, rr := range diffs {
+		if v, ok := rr.(*dns.SOA); ok {
+			// the old SOA starts the deleted records, the new SOA the added ones
+			adding = !adding
+
+			if !adding && v.Serial != soa.Serial {
+				return fmt.Errorf("difference sequence starts at serial %d, zone has serial %d", v.Serial, soa.Serial)
+			}
+
+			soa = v
+
+			continue
+		}
+
+		if adding {
+			records[recordKey(rr)] = rr
+		} else {
+			delete(records, recordKey(rr))
+		}
+	}
+
+	if !adding {
+		return ErrIncompleteTransfer
+	}
+
+	rrs := make([]dns.RR, 0, len(records)+1)
+	rrs = append(rrs, soa)
+
+	for _, rr := range records {
+		rrs = append(rrs, rr)
+	}
+
+	return z.setRecords(rrs)
+}
+
+// recordKey identifies a record by owner, type and data, but not by TTL
+func recordKey(rr dns.RR) string {
+	rr = dns.Copy(rr)
+	rr.Header().Name = dns.CanonicalName(rr.Header().Name)
+	rr.Header().Ttl = 0
+
+	return rr.String()
+}
@@ -0,0 +1,339 @@
+package zone
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// Update applies a dynamic update (RFC 2136) atomically and returns the response code.
+//
+// The prerequisites are in the answer section of msg, the updates in the authority section.
+// If the zone changes, its serial is incremented and commit is called with the differences
+// in IXFR format before they are applied. If commit fails, the zone is unchanged.
+func (z *Zone) Update(msg *dns.Msg, commit func(diffs []dns.RR) error) (int, error) {
+	z.update.Lock()
+	defer z.update.Unlock()
+
+	if rcode := z.checkPrerequisites(msg.Answer); rcode != dns.RcodeSuccess {
+		return rcode, nil
+	}
+
+	if rcode := z.prescan(msg.Ns); rcode != dns.RcodeSuccess {
+		return rcode, nil
+	}
+
+	z.lock.RLock()
+	current := z.records()
+	z.lock.RUnlock()
+
+	oldSOA := current[0].(*dns.SOA)
+	set := newUpdateSet(z.origin, current)
+
+	for _, rr := range msg.Ns {
+		set.apply(rr)
+	}
+
+	newSOA := set.soa
+
+	if newSOA == oldSOA {
+		newSOA = dns.Copy(oldSOA).(*dns.SOA)
+		newSOA.Serial++
+	}
+
+	rrs := append([]dns.RR{newSOA}, set.records()...)
+
+	diffs := differences(oldSOA, newSOA, current[1:], rrs[1:])
+	if len(diffs) == 2 && set.soa == oldSOA { //nolint:mnd // only the SOAs
+		// nothing changed
+		return dns.RcodeSuccess, nil
+	}
+
+	if err := commit(diffs); err != nil {
+		return dns.RcodeServerFailure, err
+	}
+
+	if err := z.setRecords(rrs); err != nil {
+		return dns.RcodeServerFailure, err
+	}
+
+	return dns.RcodeSuccess, nil
+}
+
+// checkPrerequisites checks the prerequisite section of an update (RFC 2136 section 3.2)
+func (z *Zone) checkPrerequisites(prerequisites []dns.RR) int {
+	z.lock.RLock()
+	defer z.lock.RUnlock()
+
+	// value dependent prerequisites, by name and type
+	expected := make(map[string][]string)
+
+	for _, rr := range prerequisites {
+		hdr := rr.Header()
+		name := dns.CanonicalName(hdr.Name)
+
+		if hdr.Ttl != 0 {
+			return dns.RcodeFormatError
+		}
+
+		if !z.Contains(name) {
+			return dns.RcodeNotZone
+		}
+
+		n := z.nodes[name]
+
+		switch hdr.Class {
+		case dns.ClassANY:
+			if hdr.Rrtype == dns.TypeANY && len(n) == 0 {
+				return dns.RcodeNameError
+			}
+
+			if hdr.Rrtype != dns.TypeANY && len(n[hdr.Rrtype]) == 0 {
+				return dns.RcodeNXRrset
+			}
+
+		case dns.ClassNONE:
+			if hdr.Rrtype == dns.TypeANY && len(n) != 0 {
+				return dns.RcodeYXDomain
+			}
+
+			if hdr.Rrtype != dns.TypeANY && len(n[hdr.Rrtype]) != 0 {
+				return dns.RcodeYXRrset
+			}
+
+		case dns.ClassINET:
+			key := name + "/" + dns.TypeToString[hdr.Rrtype]
+			expected[key] = append(expected[key], recordKey(rr))
+
+		default:
+			return dns.RcodeFormatError
+		}
+	}
+
+	for key, values := range expected {
+		name, rrType, _ := strings.Cut(key, "/")
+
+		actual := make([]string, 0, len(values))
+
+		for _, rr := range z.nodes[name][dns.StringToType[rrType]] {
+			actual = append(actual, recordKey(rr))
+		}
+
+		slices.Sort(values)
+		values = slices.Compact(values)
+		slices.Sort(actual)
+
+		if !slices.Equal(values, actual) {
+			return dns.RcodeNXRrset
+		}
+	}
+
+	return dns.RcodeSuccess
+}
+
+// prescan checks the update section of an update (RFC 2136 section 3.4.1)
+func (z *Zone) prescan(updates []dns.RR) int {
+	for _, rr := range updates {
+		hdr := rr.Header()
+
+		if !z.Contains(hdr.Name) {
+			return dns.RcodeNotZone
+		}
+
+		switch hdr.Rrtype {
+		case dns.TypeAXFR, dns.TypeIXFR, dns.TypeMAILA, dns.TypeMAILB, dns.TypeOPT, dns.TypeTSIG:
+			return dns.RcodeFormatError
+		}
+
+		switch hdr.Class {
+		case dns.ClassINET:
+			if hdr.Rrtype == dns.TypeANY {
+				return dns.RcodeFormatError
+			}
+
+		case dns.ClassANY:
+			if hdr.Ttl != 0 || hdr.Rdlength != 0 {
+				return dns.RcodeFormatError
+			}
+
+		case dns.ClassNONE:
+			if hdr.Ttl != 0 || hdr.Rrtype == dns.TypeANY {
+				return dns.RcodeFormatError
+			}
+
+		default:
+			return dns.RcodeFormatError
+		}
+	}
+
+	return dns.RcodeSuccess
+}
+
+// updateSet holds the records of a zone while the updates are applied
+type updateSet struct {
+	origin string
+	soa    *dns.SOA
+	// records without the SOA, by recordKey
+	rrs map[string]dns.RR
+}
+
+// newUpdateSet creates a set of the records, which must start with the SOA
+func newUpdateSet(origin string, records []dns.RR) *updateSet {
+	set := &updateSet{
+		origin: origin,
+		soa:    records[0].(*dns.SOA),
+		rrs:    make(map[string]dns.RR, len(records)),
+	}
+
+	for _, rr := range records[1:] {
+		// copied, as the TTLs can change
+		set.rrs[recordKey(rr)] = dns.Copy(rr)
+	}
+
+	return set
+}
+
+// apply applies a single update (RFC 2136 section 3.4.2)
+func (s *updateSet) apply(rr dns.RR) {
+	hdr := rr.Header()
+	name := dns.CanonicalName(hdr.Name)
+	isApex := name == s.origin
+
+	switch hdr.Class {
+	case dns.ClassINET:
+		s.add(rr)
+
+	case dns.ClassANY:
+		s.deleteMatching(func(existing dns.RR) bool {
+			existingType := existing.Header().Rrtype
+
+			// the SOA and NS records of the apex can't be deleted this way
+			if isApex && existingType == dns.TypeNS {
+				return false
+			}
+
+			return existing.Header().Name == name && (hdr.Rrtype == dns.TypeANY || hdr.Rrtype == existingType)
+		})
+
+	case dns.ClassNONE:
+		if isApex && hdr.Rrtype == dns.TypeNS && s.count(name, dns.TypeNS) <= 1 {
+			// the zone must keep at least one name server
+			return
+		}
+
+		rr = dns.Copy(rr)
+		rr.Header().Class = dns.ClassINET
+
+		delete(s.rrs, recordKey(rr))
+	}
+}
+
+func (s *updateSet) add(rr dns.RR) {
+	rr = dns.Copy(rr)
+	hdr := rr.Header()
+	hdr.Name = dns.CanonicalName(hdr.Name)
+
+	if soa, ok := rr.(*dns.SOA); ok {
+		if hdr.Name == s.origin && SerialLess(s.soa.Serial, soa.Serial) {
+			s.soa = soa
+		}
+
+		return
+	}
+
+	hasCNAME := s.count(hdr.Name, dns.TypeCNAME) > 0
+
+	if hdr.Rrtype == dns.TypeCNAME {
+		if !hasCNAME && s.count(hdr.Name, dns.TypeANY) > 0 {
+			// CNAMEs can't coexist with other data
+			return
+		}
+
+		// a name has only one CNAME
+		s.deleteMatching(func(existing dns.RR) bool {
+			return existing.Header().Name == hdr.Name && existing.Header().Rrtype == dns.TypeCNAME
+		})
+	} else if hasCNAME {
+		return
+	}
+
+	s.rrs[recordKey(rr)] = rr
+
+	// all records of a RRset have the same TTL (RFC 2181 section 5.2)
+	for _, existing := range s.rrs {
+		if existing.Header().Name == hdr.Name && existing.Header().Rrtype == hdr.Rrtype {
+			existing.Header().Ttl = hdr.Ttl
+		}
+	}
+}
+
+// count returns the number of records with the name and type, or any type for dns.TypeANY
+func (s *updateSet) count(name string, rrType uint16) int {
+	count := 0
+
+	for _, rr := range s.rrs {
+		if rr.Header().Name == name && (rrType == dns.TypeANY || rr.Header().Rrtype == rrType) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (s *updateSet) deleteMatching(match func(rr dns.RR) bool) {
+	for key, rr := range s.rrs {
+		if match(rr) {
+			delete(s.rrs, key)
+		}
+	}
+}
+
+func (s *updateSet) records() []dns.RR {
+	rrs := make([]dns.RR, 0, len(s.rrs))
+
+	for _, rr := range s.rrs {
+		rrs = append(rrs, rr)
+	}
+
+	return rrs
+}
+
+// differences returns the changes from the old to the new records as IXFR difference sequence
+func differences(oldSOA, newSOA *dns.SOA, oldRRs, newRRs []dns.RR) []dns.RR {
+	oldSet := make(map[string]dns.RR, len(oldRRs))
+	newSet := make(map[string]dns.RR, len(newRRs))
+
+	for _, rr := range oldRRs {
+		oldSet[rr.String()] = rr
+	}
+
+	for _, rr := range newRRs {
+		newSet[rr.String()] = rr
+	}
+
+	result := []dns.RR{oldSOA}
+	result = appendMissing(result, oldSet, newSet)
+	result = append(result, newSOA)
+
+	return appendMissing(result, newSet, oldSet)
+}
+
+// appendMissing appends the records of a, which are not in b, sorted for a stable order
+func appendMissing(result []dns.RR, a, b map[string]dns.RR) []dns.RR {
+	keys := make([]string, 0, len(a))
+
+	for key := range a {
+		if _, ok := b[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		result = append(result, a[key])
+	}
+
+	return result
+}
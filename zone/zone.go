@@ -0,0 +1,405 @@
+package zone
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/miekg/dns"
+)
+
+// maxCNAMEChain limits the number of CNAMEs followed inside a zone
+const maxCNAMEChain = 8
+
+var (
+	ErrNoSOA       = errors.New("zone has no SOA record at the apex")
+	ErrNoNS        = errors.New("zone has no NS record at the apex")
+	ErrMultipleSOA = errors.New("zone has more than one SOA record")
+)
+
+// node holds the records of one owner name by type
+type node map[uint16][]dns.RR
+
+// Zone is an authoritative DNS zone.
+//
+// All methods are safe for concurrent use.
+type Zone struct {
+	origin string
+
+	// update serializes modifications, which read the current records
+	update sync.Mutex
+
+	lock  sync.RWMutex
+	soa   *dns.SOA
+	nodes map[string]node
+	// names contains all owner names and empty non-terminals
+	names map[string]struct{}
+}
+
+// Result is the answer of a zone to a query
+type Result struct {
+	Rcode         int
+	Authoritative bool
+	// Referral is true if the name is delegated to other name servers
+	Referral bool
+	Answer   []dns.RR
+	Ns       []dns.RR
+	Extra    []dns.RR
+}
+
+// New creates a zone from its records
+func New(origin string, rrs []dns.RR) (*Zone, error) {
+	z := &Zone{origin: dns.CanonicalName(origin)}
+
+	if err := z.setRecords(rrs); err != nil {
+		return nil, err
+	}
+
+	return z, nil
+}
+
+// Parse creates a zone from a zone file.
+// file is used to resolve relative $INCLUDE directives and in error messages.
+func Parse(origin string, r io.Reader, file string) (*Zone, error) {
+	zoneParser := dns.NewZoneParser(r, dns.CanonicalName(origin), file)
+	zoneParser.SetIncludeAllowed(true)
+
+	var rrs []dns.RR
+
+	for rr, ok := zoneParser.Next(); ok; rr, ok = zoneParser.Next() {
+		rrs = append(rrs, rr)
+	}
+
+	if err := zoneParser.Err(); err != nil {
+		return nil, err
+	}
+
+	return New(origin, rrs)
+}
+
+// Origin returns the canonical name of the zone apex
+func (z *Zone) Origin() string {
+	return z.origin
+}
+
+// SOA returns a copy of the zone's SOA record
+func (z *Zone) SOA() *dns.SOA {
+	z.lock.RLock()
+	defer z.lock.RUnlock()
+
+	return dns.Copy(z.soa).(*dns.SOA)
+}
+
+// Serial returns the serial of the zone's SOA record
+func (z *Zone) Serial() uint32 {
+	z.lock.RLock()
+	defer z.lock.RUnlock()
+
+	return z.soa.Serial
+}
+
+// Len returns the number of records in the zone
+func (z *Zone) Len() int {
+	z.lock.RLock()
+	defer z.lock.RUnlock()
+
+	count := 0
+
+	for _, n := range z.nodes {
+		for _, rrs := range n {
+			count += len(rrs)
+		}
+	}
+
+	return count
+}
+
+// Contains returns true if name is the apex or below it
+func (z *Zone) Contains(name string) bool {
+	return dns.IsSubDomain(z.origin, dns.CanonicalName(name))
+}
+
+// Records returns a copy of all records of the zone, starting with the SOA
+func (z *Zone) Records() []dns.RR {
+	z.lock.RLock()
+	defer z.lock.RUnlock()
+
+	return z.records()
+}
+
+func (z *Zone) records() []dns.RR {
+	result := []dns.RR{dns.Copy(z.soa)}
+
+	for _, n := range z.nodes {
+		for rrType, rrs := range n {
+			if rrType == dns.TypeSOA {
+				continue
+			}
+
+			for _, rr := range rrs {
+				result = append(result, dns.Copy(rr))
+			}
+		}
+	}
+
+	return result
+}
+
+// Replace replaces all records of the zone
+func (z *Zone) Replace(rrs []dns.RR) error {
+	z.update.Lock()
+	defer z.update.Unlock()
+
+	return z.setRecords(rrs)
+}
+
+func (z *Zone) setRecords(rrs []dns.RR) error {
+	nodes := make(map[string]node)
+
+	var soa *dns.SOA
+
+	for _, rr := range rrs {
+		name := dns.CanonicalName(rr.Header().Name)
+
+		if !dns.IsSubDomain(z.origin, name) {
+			return fmt.Errorf("record '%s' is outside of zone '%s'", rr.Header().Name, z.origin)
+		}
+
+		if v, ok := rr.(*dns.SOA); ok {
+			if name != z.origin {
+				return fmt.Errorf("SOA record '%s' is not at the zone apex", rr.Header().Name)
+			}
+
+			if soa != nil {
+				return ErrMultipleSOA
+			}
+
+			soa = v
+		}
+
+		n, ok := nodes[name]
+		if !ok {
+			n = make(node)
+			nodes[name] = n
+		}
+
+		rr = dns.Copy(rr)
+		rr.Header().Name = name
+
+		n[rr.Header().Rrtype] = append(n[rr.Header().Rrtype], rr)
+	}
+
+	if soa == nil {
+		return ErrNoSOA
+	}
+
+	if len(nodes[z.origin][dns.TypeNS]) == 0 {
+		return ErrNoNS
+	}
+
+	names := make(map[string]struct{}, len(nodes))
+
+	for name := range nodes {
+		// add all empty non-terminals between the name and the apex
+		for ; name != z.origin; name = parent(name) {
+			names[name] = struct{}{}
+		}
+	}
+
+	names[z.origin] = struct{}{}
+
+	z.lock.Lock()
+	defer z.lock.Unlock()
+
+	z.soa = nodes[z.origin][dns.TypeSOA][0].(*dns.SOA)
+	z.nodes = nodes
+	z.names = names
+
+	return nil
+}
+
+// Lookup answers a query for a name in the zone according to RFC 1034 section 4.3.2
+func (z *Zone) Lookup(qName string, qType uint16) *Result {
+	z.lock.RLock()
+	defer z.lock.RUnlock()
+
+	return z.lookup(qName, qType, 0)
+}
+
+func (z *Zone) lookup(qName string, qType uint16, depth int) *Result {
+	name := dns.CanonicalName(qName)
+
+	if cut := z.findDelegation(name); cut != "" && (cut != name || qType != dns.TypeDS) {
+		return z.referral(cut)
+	}
+
+	n, ok := z.nodes[name]
+	if !ok {
+		if _, exists := z.names[name]; exists {
+			// empty non-terminal
+			return z.negative(dns.RcodeSuccess)
+		}
+
+		n = z.findWildcard(name)
+		if n == nil {
+			return z.negative(dns.RcodeNameError)
+		}
+	}
+
+	return z.answer(qName, n, qType, depth)
+}
+
+// findDelegation returns the highest zone cut between the apex and name
+func (z *Zone) findDelegation(name string) string {
+	labels := dns.SplitDomainName(name)
+	apexLabels := dns.CountLabel(z.origin)
+
+	for i := len(labels) - apexLabels - 1; i >= 0; i-- {
+		cut := dns.Fqdn(strings.Join(labels[i:], "."))
+
+		if len(z.nodes[cut][dns.TypeNS]) > 0 {
+			return cut
+		}
+	}
+
+	return ""
+}
+
+// findWildcard returns the wildcard node of the closest encloser (RFC 4592)
+func (z *Zone) findWildcard(name string) node {
+	for encloser := parent(name); dns.IsSubDomain(z.origin, encloser); encloser = parent(encloser) {
+		if _, exists := z.names[encloser]; exists {
+			return z.nodes["*."+encloser]
+		}
+
+		if encloser == z.origin {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (z *Zone) answer(qName string, n node, qType uint16, depth int) *Result {
+	result := &Result{Authoritative: true}
+
+	switch {
+	case qType == dns.TypeANY:
+		for _, rrs := range n {
+			result.Answer = append(result.Answer, withOwner(rrs, qName)...)
+		}
+
+	case len(n[qType]) > 0:
+		result.Answer = withOwner(n[qType], qName)
+
+	case len(n[dns.TypeCNAME]) > 0:
+		result.Answer = withOwner(n[dns.TypeCNAME], qName)
+
+		target := n[dns.TypeCNAME][0].(*dns.CNAME).Target
+		if depth < maxCNAMEChain && z.Contains(target) {
+			next := z.lookup(target, qType, depth+1)
+
+			result.Rcode = next.Rcode
+			result.Answer = append(result.Answer, next.Answer...)
+			result.Ns = next.Ns
+			result.Extra = next.Extra
+		}
+
+		return result
+
+	default:
+		return z.negative(dns.RcodeSuccess)
+	}
+
+	result.Extra = z.additional(result.Answer)
+
+	return result
+}
+
+// negative creates a NODATA (rcode success) or NXDOMAIN response with the SOA for negative caching (RFC 2308)
+func (z *Zone) negative(rcode int) *Result {
+	soa := dns.Copy(z.soa).(*dns.SOA)
+	soa.Hdr.Ttl = min(soa.Hdr.Ttl, soa.Minttl)
+
+	return &Result{
+		Rcode:         rcode,
+		Authoritative: true,
+		Ns:            []dns.RR{soa},
+	}
+}
+
+// referral creates a response pointing to the name servers of a delegated zone
+func (z *Zone) referral(cut string) *Result {
+	ns := withOwner(z.nodes[cut][dns.TypeNS], cut)
+
+	var glue []dns.RR
+
+	for _, rr := range ns {
+		target := dns.CanonicalName(rr.(*dns.NS).Ns)
+
+		// only glue below the zone cut is needed, other addresses can be resolved
+		if dns.IsSubDomain(cut, target) {
+			glue = append(glue, z.addresses(target)...)
+		}
+	}
+
+	return &Result{
+		Referral: true,
+		Ns:       ns,
+		Extra:    glue,
+	}
+}
+
+// additional returns the in-zone addresses of the targets of NS, MX and SRV records
+func (z *Zone) additional(answer []dns.RR) []dns.RR {
+	var extra []dns.RR
+
+	for _, rr := range answer {
+		var target string
+
+		switch v := rr.(type) {
+		case *dns.NS:
+			target = v.Ns
+		case *dns.MX:
+			target = v.Mx
+		case *dns.SRV:
+			target = v.Target
+		default:
+			continue
+		}
+
+		extra = append(extra, z.addresses(dns.CanonicalName(target))...)
+	}
+
+	return extra
+}
+
+func (z *Zone) addresses(name string) []dns.RR {
+	n := z.nodes[name]
+
+	return append(withOwner(n[dns.TypeA], name), withOwner(n[dns.TypeAAAA], name)...)
+}
+
+// withOwner returns copies of rrs with the given owner name.
+// This keeps the case of the question, and expands wildcards.
+func withOwner(rrs []dns.RR, owner string) []dns.RR {
+	result := make([]dns.RR, len(rrs))
+
+	for i, rr := range rrs {
+		result[i] = dns.Copy(rr)
+		result[i].Header().Name = owner
+	}
+
+	return result
+}
+
+func parent(name string) string {
+	i, end := dns.NextLabel(name, 0)
+	if end {
+		return "."
+	}
+
+	return name[i:]
+}
@@ -0,0 +1,89 @@
+package zone
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/miekg/dns"
+)
+
+// Zones is a set of zones
+type Zones struct {
+	lock  sync.RWMutex
+	zones map[string]*Zone
+}
+
+// NewZones creates an empty set of zones
+func NewZones() *Zones {
+	return &Zones{zones: make(map[string]*Zone)}
+}
+
+// Add adds the zone, replacing a zone with the same origin
+func (zs *Zones) Add(z *Zone) {
+	zs.lock.Lock()
+	defer zs.lock.Unlock()
+
+	zs.zones[z.Origin()] = z
+}
+
+// Get returns the zone with the given origin or nil
+func (zs *Zones) Get(origin string) *Zone {
+	zs.lock.RLock()
+	defer zs.lock.RUnlock()
+
+	return zs.zones[dns.CanonicalName(origin)]
+}
+
+// Find returns the most specific zone containing name or nil
+func (zs *Zones) Find(name string) *Zone {
+	zs.lock.RLock()
+	defer zs.lock.RUnlock()
+
+	name = dns.CanonicalName(name)
+
+	for {
+		if z, ok := zs.zones[name]; ok {
+			return z
+		}
+
+		if name == "." {
+			return nil
+		}
+
+		name = parent(name)
+	}
+}
+
+// All returns all zones sorted by origin
+func (zs *Zones) All() []*Zone {
+	zs.lock.RLock()
+	defer zs.lock.RUnlock()
+
+	result := make([]*Zone, 0, len(zs.zones))
+
+	for _, z := range zs.zones {
+		result = append(result, z)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Origin() < result[j].Origin()
+	})
+
+	return result
+}
+
+// Len returns the number of zones
+func (zs *Zones) Len() int {
+	zs.lock.RLock()
+	defer zs.lock.RUnlock()
+
+	return len(zs.zones)
+}
+
+// Remove removes the zone with the given origin
+func (zs *Zones) Remove(origin string) {
+	zs.lock.Lock()
+	defer zs.lock.Unlock()
+
+	delete(zs.zones, dns.CanonicalName(origin))
+}